@@ -1,3 +0,0 @@
-// Package solver implements a general-purpose solver for boolean
-// constraint satisfiability problems.
-package solver
@@ -0,0 +1,88 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-air/gini/z"
+)
+
+// Assumption fixes whether Variables are selected for the duration
+// of a single call to a Solver, without changing the problem it was
+// constructed with.
+type Assumption struct {
+	// Identifiers identifies the Variables concerned.
+	Identifiers []Identifier
+	// Selected is true if the Variables are treated as Mandatory,
+	// and false if they are treated as Prohibited.
+	Selected bool
+}
+
+// AssumeMandatory returns an Assumption that treats the Variables
+// with the given Identifiers as if they were Mandatory.
+func AssumeMandatory(ids ...Identifier) Assumption {
+	return Assumption{Identifiers: ids, Selected: true}
+}
+
+// AssumeProhibited returns an Assumption that treats the Variables
+// with the given Identifiers as if they were Prohibited.
+func AssumeProhibited(ids ...Identifier) Assumption {
+	return Assumption{Identifiers: ids}
+}
+
+// assumptionLits returns the literals to be required in order to
+// make the provided Assumptions.
+func (s *solver) assumptionLits(assumptions []Assumption) ([]z.Lit, error) {
+	var ms []z.Lit
+	for _, a := range assumptions {
+		for _, id := range a.Identifiers {
+			m, ok := s.litMap.lits[id]
+			if _, absent := s.litMap.absent[m]; !ok || absent {
+				return nil, fmt.Errorf("variable %q not provided", id)
+			}
+			if !a.Selected {
+				m = m.Not()
+			}
+			ms = append(ms, m)
+		}
+	}
+	return ms, nil
+}
+
+// SolveAssuming behaves like Solve, but treats Variables as Mandatory
+// or Prohibited according to the provided Assumptions for this call
+// only. The problem is encoded once, so that solving it under
+// different Assumptions does not require constructing a new Solver.
+// NotSatisfiable errors contain only the applied constraints of the
+// problem that conflict with the Assumptions.
+func (s *solver) SolveAssuming(ctx context.Context, assumptions ...Assumption) ([]Variable, error) {
+	result, err := s.SolveResultAssuming(ctx, assumptions...)
+	return result.Selected, err
+}
+
+// SolveResultAssuming behaves like SolveAssuming, but returns a
+// Result describing the solution in more detail. Variables selected
+// only because of an Assumption are justified as if they were
+// Mandatory.
+func (s *solver) SolveResultAssuming(ctx context.Context, assumptions ...Assumption) (result Result, err error) {
+	defer func() {
+		// This likely indicates a bug, so discard whatever
+		// return values were produced.
+		if derr := s.litMap.Error(); derr != nil {
+			result = Result{}
+			err = derr
+		}
+	}()
+
+	ms, err := s.assumptionLits(assumptions)
+	if err != nil {
+		return Result{}, err
+	}
+
+	encode := s.prepare()
+	s.litMap.Require(ms)
+	defer s.litMap.Require(nil)
+	result, err = s.solve(ctx)
+	result.Statistics.EncodeTime = encode
+	return result, err
+}
@@ -0,0 +1,117 @@
+package solver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolveAssuming(t *testing.T) {
+	type tc struct {
+		Name        string
+		Variables   []Variable
+		Assumptions []Assumption
+		Installed   []Identifier
+		Error       string
+	}
+
+	variables := []Variable{
+		variable("a", Mandatory(), Dependency("x", "y")),
+		variable("x"),
+		variable("y", Conflict("z")),
+		variable("z"),
+	}
+
+	for _, tt := range []tc{
+		{
+			Name:      "no assumptions",
+			Variables: variables,
+			Installed: []Identifier{"a", "x"},
+		},
+		{
+			Name:        "prohibited",
+			Variables:   variables,
+			Assumptions: []Assumption{AssumeProhibited("x")},
+			Installed:   []Identifier{"a", "y"},
+		},
+		{
+			Name:        "mandatory",
+			Variables:   variables,
+			Assumptions: []Assumption{AssumeMandatory("y", "z")},
+			Error:       "constraints not satisfiable: y conflicts with z",
+		},
+		{
+			Name:        "mandatory and prohibited",
+			Variables:   variables,
+			Assumptions: []Assumption{AssumeMandatory("z"), AssumeProhibited("x")},
+			Error:       "constraints not satisfiable: a is mandatory, a requires at least one of x, y, y conflicts with z",
+		},
+		{
+			Name:        "prohibited anchor",
+			Variables:   variables,
+			Assumptions: []Assumption{AssumeProhibited("a")},
+			Error:       "constraints not satisfiable: a is mandatory",
+		},
+		{
+			Name:        "unknown variable",
+			Variables:   variables,
+			Assumptions: []Assumption{AssumeMandatory("missing")},
+			Error:       `variable "missing" not provided`,
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s, err := New(WithInput(tt.Variables), WithMinimalConflicts())
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			installed, err := s.SolveAssuming(context.TODO(), tt.Assumptions...)
+			if tt.Error != "" {
+				if ns, ok := err.(NotSatisfiable); ok {
+					sortNotSatisfiable(ns)
+				}
+				assert.EqualError(err, tt.Error)
+			} else {
+				assert.NoError(err)
+			}
+			var ids []Identifier
+			for _, variable := range installed {
+				ids = append(ids, variable.Identifier())
+			}
+			assert.Equal(tt.Installed, ids)
+
+			// The assumptions do not outlive the call.
+			installed, err = s.Solve(context.TODO())
+			assert.NoError(err)
+			ids = nil
+			for _, variable := range installed {
+				ids = append(ids, variable.Identifier())
+			}
+			assert.Equal([]Identifier{"a", "x"}, ids)
+		})
+	}
+}
+
+func TestSolveResultAssumingJustifications(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := New(WithInput([]Variable{
+		variable("a", Dependency("x")),
+		variable("x"),
+	}))
+	if err != nil {
+		t.Fatalf("failed to initialize solver: %s", err)
+	}
+
+	result, err := s.SolveResultAssuming(context.TODO(), AssumeMandatory("a"))
+	assert.NoError(err)
+
+	var why []string
+	for _, j := range result.Why("x") {
+		why = append(why, j.Constraint.String())
+	}
+	assert.Equal([]string{"a is mandatory", "a requires at least one of x"}, why)
+}
@@ -0,0 +1,114 @@
+package solver
+
+import (
+	"time"
+
+	"github.com/go-air/gini/inter"
+	"github.com/go-air/gini/z"
+)
+
+// Backend is the incremental SAT solver on which a Solver relies. The
+// default Backend is provided by gini, and any inter.S satisfies it.
+// Results are reported using the gini convention of 1 for
+// satisfiable, -1 for unsatisfiable and 0 for unknown.
+type Backend interface {
+	// Add adds a literal to the clause under construction, or
+	// completes the clause if m is z.LitNull.
+	Add(m z.Lit)
+	// Assume assumes the provided literals to be true until the
+	// next call to Solve, or until the next call to Untest if
+	// they are followed by a call to Test.
+	Assume(ms ...z.Lit)
+	// Test opens a new scope containing the current assumptions
+	// and returns the result of unit propagation, appending
+	// propagated literals to dst.
+	Test(dst []z.Lit) (int, []z.Lit)
+	// Untest closes the scope opened by the most recent call to
+	// Test.
+	Untest() int
+	// Solve determines whether the clauses are satisfiable under
+	// the current assumptions.
+	Solve() int
+	// Value returns the value of m in the satisfying assignment
+	// found by the most recent call to Solve or Test.
+	Value(m z.Lit) bool
+	// Why appends to dst the assumptions that contributed to the
+	// most recent unsatisfiable result.
+	Why(dst []z.Lit) []z.Lit
+}
+
+// WithBackend returns an Option that causes the Solver to use the
+// provided Backend in place of a new instance of gini. The Backend
+// must not have been given any clauses. If it also implements
+// inter.GoSolvable, it is used to stop solving as soon as the Context
+// passed to Solve is done; otherwise, cancellation is only observed
+// between calls to Solve.
+func WithBackend(b Backend) Option {
+	return func(s *solver) error {
+		s.g = b
+		return nil
+	}
+}
+
+var _ Backend = inter.S(nil)
+
+// counter is a Backend that counts the clauses added to, and the
+// calls made to, another Backend.
+type counter struct {
+	Backend
+	clauses int
+	solves  int
+	tests   int
+}
+
+func (c *counter) Add(m z.Lit) {
+	if m == z.LitNull {
+		c.clauses++
+	}
+	c.Backend.Add(m)
+}
+
+func (c *counter) Test(dst []z.Lit) (int, []z.Lit) {
+	c.tests++
+	return c.Backend.Test(dst)
+}
+
+func (c *counter) Solve() int {
+	c.solves++
+	return c.Backend.Solve()
+}
+
+// GoSolve solves in the background if the underlying Backend is an
+// inter.GoSolvable, and otherwise solves before returning.
+func (c *counter) GoSolve() inter.Solve {
+	if gsv, ok := c.Backend.(inter.GoSolvable); ok {
+		c.solves++
+		return gsv.GoSolve()
+	}
+	return solved(c.Solve())
+}
+
+// solved is an inter.Solve whose result is already known.
+type solved int
+
+func (s solved) Stop() int {
+	return int(s)
+}
+
+func (s solved) Try(time.Duration) int {
+	return int(s)
+}
+
+func (s solved) Test() (int, bool) {
+	return int(s), true
+}
+
+func (s solved) Pause() (int, bool) {
+	return int(s), true
+}
+
+func (s solved) Unpause() {}
+
+func (s solved) Wait() int {
+	return int(s)
+}
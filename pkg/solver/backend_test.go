@@ -0,0 +1,94 @@
+package solver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-air/gini"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingBackend is a Backend that counts calls to Solve. It hides
+// every method of the wrapped solver beyond those of Backend.
+type countingBackend struct {
+	Backend
+	solves int
+}
+
+func (b *countingBackend) Solve() int {
+	b.solves++
+	return b.Backend.Solve()
+}
+
+func TestWithBackend(t *testing.T) {
+	type tc struct {
+		Name      string
+		Variables []Variable
+		Context   func() context.Context
+		Installed []Identifier
+		Error     error
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "solution is found",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("b", "c")),
+				variable("b", Conflict("a")),
+				variable("c"),
+			},
+			Installed: []Identifier{"a", "c"},
+		},
+		{
+			Name: "cancellable context",
+			Variables: []Variable{
+				variable("a", Mandatory(), AtMost(1, "b", "c"), Dependency("b")),
+				variable("b"),
+				variable("c"),
+			},
+			Context: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				t.Cleanup(cancel)
+				return ctx
+			},
+			Installed: []Identifier{"a", "b"},
+		},
+		{
+			Name: "cancelled context",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+			},
+			Context: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			Error: ErrIncomplete,
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			ctx := context.Background()
+			if tt.Context != nil {
+				ctx = tt.Context()
+			}
+
+			b := &countingBackend{Backend: gini.New()}
+			s, err := New(WithInput(tt.Variables), WithBackend(b))
+			require.NoError(t, err)
+
+			installed, err := s.Solve(ctx)
+			assert.Equal(tt.Error, err)
+			var ids []Identifier
+			for _, variable := range installed {
+				ids = append(ids, variable.Identifier())
+			}
+			assert.Equal(tt.Installed, ids)
+			if tt.Error == nil {
+				assert.NotZero(b.solves)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package solver
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+var BenchmarkInput = func() []Variable {
+	const (
+		length      = 256
+		seed        = 9
+		pMandatory  = .1
+		pDependency = .15
+		nDependency = 6
+		pConflict   = .05
+		nConflict   = 3
+	)
+
+	id := func(i int) Identifier {
+		return Identifier(strconv.Itoa(i))
+	}
+
+	variable := func(i int) TestVariable {
+		var c []Constraint
+		if rand.Float64() < pMandatory {
+			c = append(c, Mandatory())
+		}
+		if rand.Float64() < pDependency {
+			n := rand.Intn(nDependency-1) + 1
+			var d []Identifier
+			for x := 0; x < n; x++ {
+				y := i
+				for y == i {
+					y = rand.Intn(length)
+				}
+				d = append(d, id(y))
+			}
+			c = append(c, Dependency(d...))
+		}
+		if rand.Float64() < pConflict {
+			n := rand.Intn(nConflict-1) + 1
+			for x := 0; x < n; x++ {
+				y := i
+				for y == i {
+					y = rand.Intn(length)
+				}
+				c = append(c, Conflict(id(y)))
+			}
+		}
+		return TestVariable{
+			identifier:  id(i),
+			constraints: c,
+		}
+	}
+
+	rand.Seed(seed)
+	result := make([]Variable, length)
+	for i := range result {
+		result[i] = variable(i)
+	}
+	return result
+}()
+
+func BenchmarkSolve(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		s, err := New(WithInput(BenchmarkInput))
+		if err != nil {
+			b.Fatalf("failed to initialize solver: %s", err)
+		}
+		_, err = s.Solve(context.Background())
+		if err != nil {
+			b.Fatalf("failed to initialize solver: %s", err)
+		}
+	}
+}
+
+func BenchmarkNewInput(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, err := New(WithInput(BenchmarkInput))
+		if err != nil {
+			b.Fatalf("failed to initialize solver: %s", err)
+		}
+	}
+}
+
+// benchmarkChanges returns, for each of n successive changes to
+// BenchmarkInput, the Variable that replaces an existing one. Each
+// change toggles the constraints of a single Variable between their
+// original value and none, which keeps the problem satisfiable.
+func benchmarkChanges(n int) []Variable {
+	current := make([]Variable, len(BenchmarkInput))
+	copy(current, BenchmarkInput)
+	result := make([]Variable, n)
+	for i := range result {
+		k := i % len(current)
+		if len(current[k].Constraints()) > 0 {
+			current[k] = TestVariable{identifier: current[k].Identifier()}
+		} else {
+			current[k] = BenchmarkInput[k]
+		}
+		result[i] = current[k]
+	}
+	return result
+}
+
+func BenchmarkResolve(b *testing.B) {
+	changes := benchmarkChanges(b.N)
+	input := make([]Variable, len(BenchmarkInput))
+	copy(input, BenchmarkInput)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		input[i%len(input)] = changes[i]
+		s, err := New(WithInput(input))
+		if err != nil {
+			b.Fatalf("failed to initialize solver: %s", err)
+		}
+		_, err = s.Solve(context.Background())
+		if err != nil {
+			b.Fatalf("failed to solve: %s", err)
+		}
+	}
+}
+
+func BenchmarkResolveIncremental(b *testing.B) {
+	changes := benchmarkChanges(b.N)
+	s, err := New(WithInput(BenchmarkInput))
+	if err != nil {
+		b.Fatalf("failed to initialize solver: %s", err)
+	}
+	_, err = s.Solve(context.Background())
+	if err != nil {
+		b.Fatalf("failed to solve: %s", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := s.AddVariables(changes[i]); err != nil {
+			b.Fatalf("failed to change problem: %s", err)
+		}
+		_, err = s.Solve(context.Background())
+		if err != nil {
+			b.Fatalf("failed to solve: %s", err)
+		}
+	}
+}
@@ -0,0 +1,880 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-air/gini/logic"
+	"github.com/go-air/gini/z"
+)
+
+// Constraint implementations limit the circumstances under which a
+// particular Variable can appear in a solution.
+//
+// In addition to the built-in Constraints provided by this package,
+// callers may supply their own implementations. Any Constraint whose
+// Apply method returns a literal other than z.LitNull is assumed to
+// hold during Solve, and is reported as part of NotSatisfiable if it
+// contributes to a conflict.
+type Constraint interface {
+	// String returns a human-readable description of the
+	// Constraint as it applies to the Variable identified by
+	// subject.
+	String(subject Identifier) string
+	// Apply encodes the Constraint as it applies to the Variable
+	// identified by subject into the circuit c, using lm to look
+	// up the literals of referenced Variables. It returns the
+	// literal that is true exactly when the Constraint holds, or
+	// z.LitNull if the Constraint has no representation in the
+	// circuit.
+	Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit
+	// Order returns the Identifiers of Variables that are
+	// candidates for satisfying the Constraint, in decreasing
+	// order of preference. Once the subject is selected during
+	// search, the candidates are guessed in this order.
+	Order() []Identifier
+	// Anchor returns true if the subject of the Constraint must
+	// appear in every solution, making it a starting point for
+	// search.
+	Anchor() bool
+}
+
+// LitMapping provides Constraint and Objective implementations with
+// the literals that represent Variables in the circuit. It is only
+// implemented by this package.
+type LitMapping interface {
+	// LitOf returns the positive literal corresponding to the
+	// Variable with the given Identifier. Referencing an
+	// Identifier that is not part of the input causes Solve to
+	// return an error.
+	LitOf(id Identifier) z.Lit
+	// Identifiers returns the Identifiers of all input
+	// Variables, in input order.
+	Identifiers() []Identifier
+}
+
+// zeroConstraint is returned by ConstraintOf in error cases.
+type zeroConstraint struct{}
+
+var _ Constraint = zeroConstraint{}
+
+func (zeroConstraint) String(subject Identifier) string {
+	return ""
+}
+
+func (zeroConstraint) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	return z.LitNull
+}
+
+func (zeroConstraint) Order() []Identifier {
+	return nil
+}
+
+func (zeroConstraint) Anchor() bool {
+	return false
+}
+
+// AppliedConstraint values compose a single Constraint with the
+// Variable it applies to. Variable is nil for global Constraints.
+type AppliedConstraint struct {
+	Variable   Variable
+	Constraint Constraint
+}
+
+// String implements fmt.Stringer and returns a human-readable message
+// representing the receiver.
+func (a AppliedConstraint) String() string {
+	if a.Variable == nil {
+		return a.Constraint.String("")
+	}
+	return a.Constraint.String(a.Variable.Identifier())
+}
+
+type mandatory struct{}
+
+func (constraint mandatory) String(subject Identifier) string {
+	if subject == "" {
+		return "a solution is required"
+	}
+	return fmt.Sprintf("%s is mandatory", subject)
+}
+
+func (constraint mandatory) Apply(_ *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	return lm.LitOf(subject)
+}
+
+func (constraint mandatory) Order() []Identifier {
+	return nil
+}
+
+func (constraint mandatory) Anchor() bool {
+	return true
+}
+
+// Mandatory returns a Constraint that will permit only solutions that
+// contain a particular Variable.
+func Mandatory() Constraint {
+	return mandatory{}
+}
+
+type prohibited struct{}
+
+func (constraint prohibited) String(subject Identifier) string {
+	if subject == "" {
+		return "no solution is permitted"
+	}
+	return fmt.Sprintf("%s is prohibited", subject)
+}
+
+func (constraint prohibited) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	return lm.LitOf(subject).Not()
+}
+
+func (constraint prohibited) Order() []Identifier {
+	return nil
+}
+
+func (constraint prohibited) Anchor() bool {
+	return false
+}
+
+// Prohibited returns a Constraint that will reject any solution that
+// contains a particular Variable. Callers may also decide to omit
+// an Variable from input to Solve rather than apply such a
+// Constraint.
+func Prohibited() Constraint {
+	return prohibited{}
+}
+
+type dependency []Identifier
+
+func (constraint dependency) String(subject Identifier) string {
+	if len(constraint) == 0 {
+		if subject == "" {
+			return "a dependency without any candidates to satisfy it is required"
+		}
+		return fmt.Sprintf("%s has a dependency without any candidates to satisfy it", subject)
+	}
+	s := make([]string, len(constraint))
+	for i, each := range constraint {
+		s[i] = string(each)
+	}
+	if subject == "" {
+		return fmt.Sprintf("at least one of %s is required", strings.Join(s, ", "))
+	}
+	return fmt.Sprintf("%s requires at least one of %s", subject, strings.Join(s, ", "))
+}
+
+func (constraint dependency) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	m := lm.LitOf(subject).Not()
+	for _, each := range constraint {
+		m = c.Or(m, lm.LitOf(each))
+	}
+	return m
+}
+
+func (constraint dependency) Order() []Identifier {
+	return constraint
+}
+
+func (constraint dependency) Anchor() bool {
+	return false
+}
+
+// Dependency returns a Constraint that will only permit solutions
+// containing a given Variable on the condition that at least one
+// of the Variables identified by the given Identifiers also
+// appears in the solution. Identifiers appearing earlier in the
+// argument list have higher preference than those appearing later.
+func Dependency(ids ...Identifier) Constraint {
+	return dependency(ids)
+}
+
+type conflict Identifier
+
+func (constraint conflict) String(subject Identifier) string {
+	if subject == "" {
+		return fmt.Sprintf("%s is prohibited", Identifier(constraint))
+	}
+	return fmt.Sprintf("%s conflicts with %s", subject, constraint)
+}
+
+func (constraint conflict) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	return c.Or(lm.LitOf(subject).Not(), lm.LitOf(Identifier(constraint)).Not())
+}
+
+func (constraint conflict) Order() []Identifier {
+	return nil
+}
+
+func (constraint conflict) Anchor() bool {
+	return false
+}
+
+// Conflict returns a Constraint that will permit solutions containing
+// either the constrained Variable, the Variable identified by
+// the given Identifier, or neither, but not both.
+func Conflict(id Identifier) Constraint {
+	return conflict(id)
+}
+
+type leq struct {
+	ids []Identifier
+	n   int
+}
+
+func (constraint leq) String(subject Identifier) string {
+	s := make([]string, len(constraint.ids))
+	for i, each := range constraint.ids {
+		s[i] = string(each)
+	}
+	if subject == "" {
+		return fmt.Sprintf("at most %d of %s are permitted", constraint.n, strings.Join(s, ", "))
+	}
+	return fmt.Sprintf("%s permits at most %d of %s", subject, constraint.n, strings.Join(s, ", "))
+}
+
+func (constraint leq) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	ms := make([]z.Lit, len(constraint.ids))
+	for i, each := range constraint.ids {
+		ms[i] = lm.LitOf(each)
+	}
+	return c.CardSort(ms).Leq(constraint.n)
+}
+
+func (constraint leq) Order() []Identifier {
+	return nil
+}
+
+func (constraint leq) Anchor() bool {
+	return false
+}
+
+// AtMost returns a Constraint that forbids solutions that contain
+// more than n of the Variables identified by the given
+// Identifiers.
+func AtMost(n int, ids ...Identifier) Constraint {
+	return leq{
+		ids: ids,
+		n:   n,
+	}
+}
+
+type geq struct {
+	ids []Identifier
+	n   int
+}
+
+func (constraint geq) String(subject Identifier) string {
+	s := make([]string, len(constraint.ids))
+	for i, each := range constraint.ids {
+		s[i] = string(each)
+	}
+	if subject == "" {
+		return fmt.Sprintf("at least %d of %s are required", constraint.n, strings.Join(s, ", "))
+	}
+	return fmt.Sprintf("%s requires at least %d of %s", subject, constraint.n, strings.Join(s, ", "))
+}
+
+func (constraint geq) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	ms := make([]z.Lit, len(constraint.ids))
+	for i, each := range constraint.ids {
+		ms[i] = lm.LitOf(each)
+	}
+	return c.Implies(lm.LitOf(subject), c.CardSort(ms).Geq(constraint.n))
+}
+
+func (constraint geq) Order() []Identifier {
+	if constraint.n <= 0 {
+		return nil
+	}
+	return constraint.ids
+}
+
+func (constraint geq) Anchor() bool {
+	return false
+}
+
+// AtLeast returns a Constraint that will only permit solutions
+// containing a given Variable on the condition that at least n of
+// the Variables identified by the given Identifiers also appear in
+// the solution. As with Dependency, the search first attempts to
+// satisfy the Constraint with Identifiers appearing earlier in the
+// argument list, but only the first candidate to be selected is
+// chosen by preference.
+func AtLeast(n int, ids ...Identifier) Constraint {
+	return geq{
+		ids: ids,
+		n:   n,
+	}
+}
+
+type exactly struct {
+	ids []Identifier
+	n   int
+}
+
+func (constraint exactly) String(subject Identifier) string {
+	s := make([]string, len(constraint.ids))
+	for i, each := range constraint.ids {
+		s[i] = string(each)
+	}
+	if subject == "" {
+		return fmt.Sprintf("exactly %d of %s are required", constraint.n, strings.Join(s, ", "))
+	}
+	return fmt.Sprintf("%s requires exactly %d of %s", subject, constraint.n, strings.Join(s, ", "))
+}
+
+func (constraint exactly) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	ms := make([]z.Lit, len(constraint.ids))
+	for i, each := range constraint.ids {
+		ms[i] = lm.LitOf(each)
+	}
+	cs := c.CardSort(ms)
+	return c.Implies(lm.LitOf(subject), c.And(cs.Geq(constraint.n), cs.Leq(constraint.n)))
+}
+
+func (constraint exactly) Order() []Identifier {
+	if constraint.n <= 0 {
+		return nil
+	}
+	return constraint.ids
+}
+
+func (constraint exactly) Anchor() bool {
+	return false
+}
+
+// Exactly returns a Constraint that will only permit solutions
+// containing a given Variable on the condition that exactly n of the
+// Variables identified by the given Identifiers also appear in the
+// solution. As with AtLeast, only the first candidate to be selected
+// is chosen by preference.
+func Exactly(n int, ids ...Identifier) Constraint {
+	return exactly{
+		ids: ids,
+		n:   n,
+	}
+}
+
+// Term pairs the Identifier of a Variable with an integer
+// coefficient, for use in weighted Constraints.
+type Term struct {
+	Identifier  Identifier
+	Coefficient int
+}
+
+// weightedTerm is a literal with a positive weight.
+type weightedTerm struct {
+	m z.Lit
+	w int
+}
+
+// weightedSum encodes bounds on the sum of the weights of the true
+// literals among a set of weighted terms. Weights are first divided
+// by their greatest common divisor. If every weight is then one, the
+// sum is counted by a sorting network. Otherwise, if the result is
+// small enough, bounds are encoded as a reduced ordered decision
+// diagram, whose nodes are each shared by all of the bounds for
+// which they have the same meaning, including bounds requested by
+// separate calls to Leq. Failing that, the sum is encoded in binary
+// by a network of adders, and each bound by a comparison against its
+// binary digits.
+type weightedSum struct {
+	c     *logic.C
+	terms []weightedTerm  // in decreasing order of weight
+	scale int             // common divisor of the original weights
+	rest  []int           // total weight of terms i and later
+	cs    *logic.CardSort // sorting network over the terms, if not nil
+	nodes [][]bddNode     // decision diagram nodes for terms i and later, by bound
+	bits  []z.Lit         // binary digits of the sum, least significant first, if not nil
+}
+
+// bddNode is a literal that is true exactly when the sum of the
+// weights of the true terms from some index onwards is at most k,
+// for every k from lo to hi.
+type bddNode struct {
+	lo, hi int
+	m      z.Lit
+}
+
+// bddLimit is the greatest number of nodes a decision diagram may be
+// estimated to require before a weightedSum is encoded by adders
+// instead.
+const bddLimit = 1 << 16
+
+// unbounded exceeds any bound that can be reached by a sum of
+// weights, while leaving room to add a weight without overflowing.
+const unbounded = math.MaxInt / 2
+
+// newWeightedSum returns a weightedSum over the provided terms,
+// ignoring those with non-positive weights. The encoding is chosen
+// according to bound, which is the greatest bound expected to be
+// passed to Leq.
+func newWeightedSum(c *logic.C, terms []weightedTerm, bound int) *weightedSum {
+	s := weightedSum{c: c}
+	for _, each := range terms {
+		if each.w > 0 {
+			s.terms = append(s.terms, each)
+			s.scale = gcd(s.scale, each.w)
+		}
+	}
+	if s.scale == 0 {
+		s.scale = 1
+	}
+	for i := range s.terms {
+		s.terms[i].w /= s.scale
+	}
+	sort.SliceStable(s.terms, func(i, j int) bool {
+		return s.terms[i].w > s.terms[j].w
+	})
+	s.rest = make([]int, len(s.terms)+1)
+	for i := len(s.terms) - 1; i >= 0; i-- {
+		s.rest[i] = s.rest[i+1] + s.terms[i].w
+	}
+	s.nodes = make([][]bddNode, len(s.terms))
+	if len(s.terms) > 0 && s.rest[0] == len(s.terms) {
+		ms := make([]z.Lit, len(s.terms))
+		for i, each := range s.terms {
+			ms[i] = each.m
+		}
+		s.cs = c.CardSort(ms)
+		return &s
+	}
+
+	// A level of the decision diagram has at most one node per
+	// subset of the terms before it, and at most one per bound.
+	if bound /= s.scale; bound > s.rest[0] {
+		bound = s.rest[0]
+	}
+	size := 0
+	for i := range s.terms {
+		if i < 62 && 1<<i <= bound {
+			size += 1 << i
+		} else {
+			size += bound + 1
+		}
+		if size > bddLimit {
+			s.encodeBinary()
+			break
+		}
+	}
+	return &s
+}
+
+// gcd returns the greatest common divisor of a and b, which must not
+// be negative.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// encodeBinary encodes the sum in binary, so that bounds are
+// encoded by comparing against its digits rather than by a decision
+// diagram.
+func (s *weightedSum) encodeBinary() {
+	// Each column holds literals whose total, times the
+	// column's place value, contributes to the sum. Reduce each
+	// column to a single digit, carrying into the next one.
+	var columns [][]z.Lit
+	for _, each := range s.terms {
+		for j := 0; each.w>>j > 0; j++ {
+			if each.w>>j&1 == 0 {
+				continue
+			}
+			for len(columns) <= j {
+				columns = append(columns, nil)
+			}
+			columns[j] = append(columns[j], each.m)
+		}
+	}
+	s.bits = make([]z.Lit, 0, len(columns))
+	for j := 0; j < len(columns); j++ {
+		column := columns[j]
+		for len(column) > 1 {
+			a, b := column[0], column[1]
+			sum, carry := s.c.Xor(a, b), s.c.And(a, b)
+			column = column[2:]
+			if len(column) > 0 {
+				// full adder
+				x := column[0]
+				column = column[1:]
+				carry = s.c.Or(carry, s.c.And(sum, x))
+				sum = s.c.Xor(sum, x)
+			}
+			column = append(column, sum)
+			if j+1 == len(columns) {
+				columns = append(columns, nil)
+			}
+			columns[j+1] = append(columns[j+1], carry)
+		}
+		if len(column) == 0 {
+			column = append(column, s.c.F)
+		}
+		s.bits = append(s.bits, column[0])
+	}
+}
+
+// Max returns the greatest value the sum can take.
+func (s *weightedSum) Max() int {
+	return s.rest[0] * s.scale
+}
+
+// Value returns the sum of the weights of the terms that are true
+// according to value.
+func (s *weightedSum) Value(value func(z.Lit) bool) int {
+	sum := 0
+	for _, each := range s.terms {
+		if value(each.m) {
+			sum += each.w
+		}
+	}
+	return sum * s.scale
+}
+
+// Leq returns a literal that is true exactly when the sum is at most
+// k.
+func (s *weightedSum) Leq(k int) z.Lit {
+	if k < 0 {
+		return s.c.F
+	}
+	k /= s.scale
+	if k >= s.rest[0] {
+		return s.c.T
+	}
+	if s.cs != nil {
+		return s.cs.Leq(k)
+	}
+	if s.bits == nil {
+		return s.leq(0, k).m
+	}
+
+	// Compare digits from the least significant upwards, so
+	// that m holds when the digits so far are at most those
+	// of k.
+	m := s.c.T
+	for j, bit := range s.bits {
+		if k>>j&1 == 1 {
+			m = s.c.Or(bit.Not(), m)
+		} else {
+			m = s.c.And(bit.Not(), m)
+		}
+	}
+	return m
+}
+
+// leq returns the node for the sum of the weights of terms i and
+// later being at most k, together with the range of bounds for which
+// it is the same node.
+func (s *weightedSum) leq(i, k int) bddNode {
+	if k < 0 {
+		return bddNode{lo: -unbounded, hi: -1, m: s.c.F}
+	}
+	if s.rest[i] <= k {
+		return bddNode{lo: s.rest[i], hi: unbounded, m: s.c.T}
+	}
+	nodes := s.nodes[i]
+	j := sort.Search(len(nodes), func(j int) bool { return nodes[j].hi >= k })
+	if j < len(nodes) && nodes[j].lo <= k {
+		return nodes[j]
+	}
+
+	t := s.terms[i]
+	then, els := s.leq(i+1, k-t.w), s.leq(i+1, k)
+	n := bddNode{
+		lo: then.lo + t.w,
+		hi: then.hi + t.w,
+		m:  then.m,
+	}
+	if els.lo > n.lo {
+		n.lo = els.lo
+	}
+	if els.hi < n.hi {
+		n.hi = els.hi
+	}
+	if then.m != els.m {
+		n.m = s.c.Choice(t.m, then.m, els.m)
+	}
+	s.nodes[i] = append(nodes[:j], append([]bddNode{n}, nodes[j:]...)...)
+	return n
+}
+
+type weightedLeq struct {
+	terms []Term
+	n     int
+}
+
+func (constraint weightedLeq) String(subject Identifier) string {
+	s := make([]string, len(constraint.terms))
+	for i, each := range constraint.terms {
+		s[i] = fmt.Sprintf("%d*%s", each.Coefficient, each.Identifier)
+	}
+	if subject == "" {
+		return fmt.Sprintf("a weighted sum of at most %d of %s is permitted", constraint.n, strings.Join(s, ", "))
+	}
+	return fmt.Sprintf("%s permits a weighted sum of at most %d of %s", subject, constraint.n, strings.Join(s, ", "))
+}
+
+func (constraint weightedLeq) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	// Rewrite negative coefficients in terms of the negated
+	// literal, so that every remaining weight is positive.
+	n := constraint.n
+	var terms []weightedTerm
+	for _, each := range constraint.terms {
+		m, w := lm.LitOf(each.Identifier), each.Coefficient
+		if w < 0 {
+			n -= w
+			m, w = m.Not(), -w
+		}
+		terms = append(terms, weightedTerm{m: m, w: w})
+	}
+	return newWeightedSum(c, terms, n).Leq(n)
+}
+
+func (constraint weightedLeq) Order() []Identifier {
+	return nil
+}
+
+func (constraint weightedLeq) Anchor() bool {
+	return false
+}
+
+// WeightedAtMost returns a Constraint that forbids solutions in which
+// the sum of the coefficients of the terms whose Variables are
+// selected exceeds n. Coefficients may be negative. Like AtMost, it
+// applies regardless of whether the Variable it is applied to is
+// selected.
+//
+// Coefficients may be as large as the quantities they represent,
+// such as memory requests in bytes: the encoded size of the
+// Constraint is bounded by the number of terms and the logarithm of
+// the coefficients, rather than by their magnitude.
+func WeightedAtMost(n int, terms ...Term) Constraint {
+	return weightedLeq{
+		terms: terms,
+		n:     n,
+	}
+}
+
+type selected Identifier
+
+func (constraint selected) String(subject Identifier) string {
+	return fmt.Sprintf("%s is selected", Identifier(constraint))
+}
+
+func (constraint selected) Apply(_ *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	return lm.LitOf(Identifier(constraint))
+}
+
+func (constraint selected) Order() []Identifier {
+	return nil
+}
+
+func (constraint selected) Anchor() bool {
+	return false
+}
+
+// Selected returns a Constraint that holds only in solutions that
+// contain the Variable identified by the given Identifier,
+// regardless of the Variable it is applied to. It is primarily
+// useful as an operand of And, Or, Not and Implies.
+func Selected(id Identifier) Constraint {
+	return selected(id)
+}
+
+// applyOperand applies a Constraint that is an operand of a
+// combinator, treating a Constraint without a representation in the
+// circuit as one that always holds.
+func applyOperand(c *logic.C, lm LitMapping, subject Identifier, constraint Constraint) z.Lit {
+	if m := constraint.Apply(c, lm, subject); m != z.LitNull {
+		return m
+	}
+	return c.T
+}
+
+// joinOperands returns the descriptions of the provided Constraints
+// joined by op and enclosed in parentheses.
+func joinOperands(subject Identifier, op string, constraints []Constraint) string {
+	s := make([]string, len(constraints))
+	for i, each := range constraints {
+		s[i] = each.String(subject)
+	}
+	return fmt.Sprintf("(%s)", strings.Join(s, fmt.Sprintf(" %s ", op)))
+}
+
+type and []Constraint
+
+func (constraint and) String(subject Identifier) string {
+	if len(constraint) == 0 {
+		return "true"
+	}
+	return joinOperands(subject, "and", constraint)
+}
+
+func (constraint and) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	m := c.T
+	for _, each := range constraint {
+		m = c.And(m, applyOperand(c, lm, subject, each))
+	}
+	return m
+}
+
+func (constraint and) Order() []Identifier {
+	return nil
+}
+
+func (constraint and) Anchor() bool {
+	for _, each := range constraint {
+		if each.Anchor() {
+			return true
+		}
+	}
+	return false
+}
+
+// And returns a Constraint that holds only when all of the given
+// Constraints hold for the Variable it is applied to. It is an
+// anchor if any of the given Constraints is an anchor.
+//
+// The preferences expressed by the Order of the given Constraints
+// are not taken into account during search; this applies to all
+// of And, Or, Not and Implies.
+func And(constraints ...Constraint) Constraint {
+	return and(constraints)
+}
+
+type or []Constraint
+
+func (constraint or) String(subject Identifier) string {
+	if len(constraint) == 0 {
+		return "false"
+	}
+	return joinOperands(subject, "or", constraint)
+}
+
+func (constraint or) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	m := c.F
+	for _, each := range constraint {
+		m = c.Or(m, applyOperand(c, lm, subject, each))
+	}
+	return m
+}
+
+func (constraint or) Order() []Identifier {
+	return nil
+}
+
+func (constraint or) Anchor() bool {
+	return false
+}
+
+// Or returns a Constraint that holds when at least one of the given
+// Constraints holds for the Variable it is applied to.
+func Or(constraints ...Constraint) Constraint {
+	return or(constraints)
+}
+
+type not struct {
+	operand Constraint
+}
+
+func (constraint not) String(subject Identifier) string {
+	s := constraint.operand.String(subject)
+	if !strings.HasPrefix(s, "(") {
+		s = fmt.Sprintf("(%s)", s)
+	}
+	return fmt.Sprintf("not %s", s)
+}
+
+func (constraint not) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	return applyOperand(c, lm, subject, constraint.operand).Not()
+}
+
+func (constraint not) Order() []Identifier {
+	return nil
+}
+
+func (constraint not) Anchor() bool {
+	return false
+}
+
+// Not returns a Constraint that holds only when the given Constraint
+// does not hold for the Variable it is applied to.
+func Not(constraint Constraint) Constraint {
+	return not{operand: constraint}
+}
+
+type implies struct {
+	condition, consequence Constraint
+}
+
+func (constraint implies) String(subject Identifier) string {
+	return fmt.Sprintf("(if %s then %s)", constraint.condition.String(subject), constraint.consequence.String(subject))
+}
+
+func (constraint implies) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	return c.Implies(
+		applyOperand(c, lm, subject, constraint.condition),
+		applyOperand(c, lm, subject, constraint.consequence),
+	)
+}
+
+func (constraint implies) Order() []Identifier {
+	return nil
+}
+
+func (constraint implies) Anchor() bool {
+	return false
+}
+
+// Implies returns a Constraint that holds when either the condition
+// does not hold or the consequence holds for the Variable it is
+// applied to.
+func Implies(condition, consequence Constraint) Constraint {
+	return implies{
+		condition:   condition,
+		consequence: consequence,
+	}
+}
+
+type soft struct {
+	constraint Constraint
+	weight     int
+}
+
+func (constraint soft) String(subject Identifier) string {
+	return fmt.Sprintf("%s (weight %d)", constraint.constraint.String(subject), constraint.weight)
+}
+
+func (constraint soft) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	return constraint.constraint.Apply(c, lm, subject)
+}
+
+func (constraint soft) Order() []Identifier {
+	return constraint.constraint.Order()
+}
+
+func (constraint soft) Anchor() bool {
+	return false
+}
+
+// Soft returns a Constraint that is permitted to be violated, at a
+// cost of the given weight. Solutions are chosen to minimize the
+// total weight of violated soft Constraints before any preference or
+// cardinality considerations, and the violated soft Constraints are
+// reported in the Result. A soft Constraint is never an anchor, and
+// a non-positive weight makes its violation free.
+//
+// Soft only has an effect when applied directly to a Variable or
+// passed to WithGlobalConstraints; as an operand of a combinator
+// such as And, it behaves like the Constraint it wraps.
+func Soft(weight int, constraint Constraint) Constraint {
+	return soft{
+		constraint: constraint,
+		weight:     weight,
+	}
+}
@@ -0,0 +1,344 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/go-air/gini/logic"
+	"github.com/go-air/gini/z"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrder(t *testing.T) {
+	type tc struct {
+		Name       string
+		Constraint Constraint
+		Expected   []Identifier
+	}
+
+	for _, tt := range []tc{
+		{
+			Name:       "mandatory",
+			Constraint: Mandatory(),
+		},
+		{
+			Name:       "prohibited",
+			Constraint: Prohibited(),
+		},
+		{
+			Name:       "dependency",
+			Constraint: Dependency("a", "b", "c"),
+			Expected:   []Identifier{"a", "b", "c"},
+		},
+		{
+			Name:       "conflict",
+			Constraint: Conflict("a"),
+		},
+		{
+			Name:       "at most",
+			Constraint: AtMost(1, "a", "b"),
+		},
+		{
+			Name:       "at least",
+			Constraint: AtLeast(2, "a", "b", "c"),
+			Expected:   []Identifier{"a", "b", "c"},
+		},
+		{
+			Name:       "at least zero",
+			Constraint: AtLeast(0, "a", "b", "c"),
+		},
+		{
+			Name:       "exactly",
+			Constraint: Exactly(1, "a", "b"),
+			Expected:   []Identifier{"a", "b"},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, tt.Constraint.Order())
+		})
+	}
+}
+
+// requiresAll is a Constraint implemented outside of the built-in
+// set, permitting its subject only if every referenced Variable is
+// also selected.
+type requiresAll []Identifier
+
+func (constraint requiresAll) String(subject Identifier) string {
+	return fmt.Sprintf("%s requires all of %v", subject, []Identifier(constraint))
+}
+
+func (constraint requiresAll) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	m := z.LitNull
+	for _, each := range constraint {
+		if m == z.LitNull {
+			m = lm.LitOf(each)
+			continue
+		}
+		m = c.And(m, lm.LitOf(each))
+	}
+	if m == z.LitNull {
+		return z.LitNull
+	}
+	return c.Implies(lm.LitOf(subject), m)
+}
+
+func (constraint requiresAll) Order() []Identifier {
+	return nil
+}
+
+func (constraint requiresAll) Anchor() bool {
+	return false
+}
+
+// pinned is a custom anchor Constraint that prefers a single
+// candidate once its subject is selected.
+type pinned Identifier
+
+func (constraint pinned) String(subject Identifier) string {
+	return fmt.Sprintf("%s is pinned to %s", subject, Identifier(constraint))
+}
+
+func (constraint pinned) Apply(c *logic.C, lm LitMapping, subject Identifier) z.Lit {
+	return c.And(lm.LitOf(subject), lm.LitOf(Identifier(constraint)))
+}
+
+func (constraint pinned) Order() []Identifier {
+	return []Identifier{Identifier(constraint)}
+}
+
+func (constraint pinned) Anchor() bool {
+	return true
+}
+
+func TestCustomConstraint(t *testing.T) {
+	type tc struct {
+		Name      string
+		Variables []Variable
+		Installed []Identifier
+		Error     error
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "custom constraints are satisfied",
+			Variables: []Variable{
+				variable("a", pinned("b")),
+				variable("b", requiresAll{"x", "y"}),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+			},
+			Installed: []Identifier{"a", "b", "x", "y"},
+		},
+		{
+			Name: "custom constraints appear in conflicts",
+			Variables: []Variable{
+				variable("a", pinned("b")),
+				variable("b", requiresAll{"x", "y"}),
+				variable("x"),
+				variable("y", Prohibited()),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", pinned("b")),
+					Constraint: pinned("b"),
+				},
+				{
+					Variable:   variable("b", requiresAll{"x", "y"}),
+					Constraint: requiresAll{"x", "y"},
+				},
+				{
+					Variable:   variable("y", Prohibited()),
+					Constraint: Prohibited(),
+				},
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s, err := New(WithInput(tt.Variables))
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			installed, err := s.Solve(context.TODO())
+
+			var ids []Identifier
+			for _, variable := range installed {
+				ids = append(ids, variable.Identifier())
+			}
+			var ns NotSatisfiable
+			if errors.As(err, &ns) {
+				sort.SliceStable(ns, func(i, j int) bool {
+					return ns[i].Variable.Identifier() < ns[j].Variable.Identifier()
+				})
+			}
+			assert.Equal(tt.Installed, ids)
+			assert.Equal(tt.Error, err)
+		})
+	}
+}
+
+func TestCombinatorString(t *testing.T) {
+	type tc struct {
+		Name       string
+		Constraint Constraint
+		Expected   string
+	}
+
+	for _, tt := range []tc{
+		{
+			Name:       "selected",
+			Constraint: Selected("b"),
+			Expected:   "b is selected",
+		},
+		{
+			Name:       "and",
+			Constraint: And(Selected("b"), Dependency("c")),
+			Expected:   "(b is selected and a requires at least one of c)",
+		},
+		{
+			Name:       "empty and",
+			Constraint: And(),
+			Expected:   "true",
+		},
+		{
+			Name:       "or",
+			Constraint: Or(Selected("b"), Conflict("c")),
+			Expected:   "(b is selected or a conflicts with c)",
+		},
+		{
+			Name:       "empty or",
+			Constraint: Or(),
+			Expected:   "false",
+		},
+		{
+			Name:       "not",
+			Constraint: Not(Selected("b")),
+			Expected:   "not (b is selected)",
+		},
+		{
+			Name:       "nested",
+			Constraint: Implies(Not(Selected("z")), And(Conflict("x"), Conflict("y"))),
+			Expected:   "(if not (z is selected) then (a conflicts with x and a conflicts with y))",
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, tt.Constraint.String("a"))
+		})
+	}
+}
+
+func TestWeightedSum(t *testing.T) {
+	type tc struct {
+		Name    string
+		Weights []int
+		Binary  bool
+	}
+
+	for _, tt := range []tc{
+		{
+			Name:    "decision diagram",
+			Weights: []int{5, 3, 3, 1, 7, 2},
+		},
+		{
+			Name:    "decision diagram with common divisor",
+			Weights: []int{512, 128, 384, 256, 1024},
+		},
+		{
+			Name:    "sorting network",
+			Weights: []int{3, 3, 3, 3, 3},
+		},
+		{
+			Name:    "adders",
+			Weights: []int{5, 3, 3, 1, 7, 2},
+			Binary:  true,
+		},
+		{
+			Name:    "adders with common divisor",
+			Weights: []int{512, 128, 384, 256, 1024, 640},
+			Binary:  true,
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			c := logic.NewC()
+			var terms []weightedTerm
+			for _, w := range tt.Weights {
+				terms = append(terms, weightedTerm{m: c.Lit(), w: w})
+			}
+			s := newWeightedSum(c, terms, 0)
+			if tt.Binary {
+				s.encodeBinary()
+			}
+
+			total := 0
+			for _, w := range tt.Weights {
+				total += w
+			}
+			assert.Equal(t, total, s.Max())
+			var ks []int
+			var ms []z.Lit
+			for k := -1; k <= total+1; k++ {
+				ks = append(ks, k)
+				ms = append(ms, s.Leq(k))
+			}
+
+			for bits := 0; bits < 1<<len(terms); bits++ {
+				vs := make([]bool, c.Len())
+				sum := 0
+				for i, each := range terms {
+					if bits>>i&1 == 1 {
+						vs[each.m.Var()] = true
+						sum += each.w
+					}
+				}
+				c.Eval(vs)
+				assert.Equal(t, sum, s.Value(func(m z.Lit) bool { return vs[m.Var()] == m.IsPos() }))
+				for i, m := range ms {
+					if vs[m.Var()] == m.IsPos() != (sum <= ks[i]) {
+						t.Fatalf("sum %d <= %d evaluated to %t", sum, ks[i], sum > ks[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWeightedAtMostEncodingSize(t *testing.T) {
+	// Memory requests in MiB of between 128MiB and 4GiB, limited
+	// to a total of 24GiB.
+	const n = 60
+	rng := rand.New(rand.NewSource(1))
+	var terms []Term
+	var variables []Variable
+	for i := 0; i < n; i++ {
+		id := Identifier(fmt.Sprintf("x%d", i))
+		terms = append(terms, Term{Identifier: id, Coefficient: 128 + rng.Intn(4096-128+1)})
+		variables = append(variables, variable(id))
+	}
+	lm, err := newLitMapping(variables)
+	assert.NoError(t, err)
+	c := logic.NewC()
+	WeightedAtMost(24576, terms...).Apply(c, lm, "")
+	assert.Less(t, c.Len(), 10*n*n)
+
+	ids := lm.Identifiers()
+	variables = append(variables, variable("a", Mandatory(), WeightedAtMost(24576, terms...), AtLeast(n/4, ids...)))
+	s, err := New(WithInput(variables))
+	assert.NoError(t, err)
+	installed, err := s.Solve(context.TODO())
+	assert.NoError(t, err)
+	var selected []Identifier
+	for _, each := range installed {
+		selected = append(selected, each.Identifier())
+	}
+	violated, err := Verify(variables, selected)
+	assert.NoError(t, err)
+	assert.Empty(t, violated)
+}
@@ -0,0 +1,73 @@
+package solver
+
+import (
+	"context"
+
+	"github.com/go-air/gini/z"
+)
+
+// minimizeConflicts returns a minimal subset of the provided
+// constraint literals that cannot be satisfied together, using
+// deletion-based minimization. Each literal is removed in turn and
+// kept out if the remainder is still unsatisfiable, in which case the
+// remainder is further reduced to the failed assumptions reported by
+// the underlying solver. If the Context is done before minimization
+// completes, the smallest unsatisfiable set found so far is returned.
+func (s *solver) minimizeConflicts(ctx context.Context, core []z.Lit) []z.Lit {
+	// The failed assumptions of a search may include guesses,
+	// without which the constraints alone may be satisfiable. In
+	// that case, start from the set of all constraints instead.
+	switch s.check(ctx, core) {
+	case satisfiable:
+		all := s.litMap.ConstraintLits()
+		if s.check(ctx, all) != unsatisfiable {
+			return core
+		}
+		core = s.refine(all)
+	case unsatisfiable:
+		core = s.refine(core)
+	default:
+		return core
+	}
+
+	for i := 0; i < len(core); {
+		candidate := make([]z.Lit, 0, len(core)-1)
+		candidate = append(candidate, core[:i]...)
+		candidate = append(candidate, core[i+1:]...)
+		switch s.check(ctx, candidate) {
+		case unsatisfiable:
+			// Every literal before i is necessary, so
+			// it is also among the failed assumptions.
+			core = s.refine(candidate)
+		case satisfiable:
+			i++
+		default:
+			return core
+		}
+	}
+	return core
+}
+
+// check solves under the provided assumptions, in addition to those
+// about required and absent Variables, and returns the result.
+func (s *solver) check(ctx context.Context, assumptions []z.Lit) int {
+	s.litMap.AssumeVariables(s.g)
+	s.g.Assume(assumptions...)
+	return solve(ctx, s.g)
+}
+
+// refine returns the members of ms, in order, that are among the
+// failed assumptions of the last unsatisfiable result.
+func (s *solver) refine(ms []z.Lit) []z.Lit {
+	why := make(map[z.Lit]struct{})
+	for _, m := range s.g.Why(nil) {
+		why[m] = struct{}{}
+	}
+	result := make([]z.Lit, 0, len(ms))
+	for _, m := range ms {
+		if _, ok := why[m]; ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/go-air/gini/z"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unsatisfiableInput returns a randomly generated problem that is
+// likely, but not certain, to be unsatisfiable.
+func unsatisfiableInput(seed int64) []Variable {
+	const length = 24
+	r := rand.New(rand.NewSource(seed))
+	id := func(i int) Identifier {
+		return Identifier(strconv.Itoa(i))
+	}
+	result := make([]Variable, length)
+	for i := range result {
+		var c []Constraint
+		if r.Float64() < .3 {
+			c = append(c, Mandatory())
+		}
+		if r.Float64() < .5 {
+			var d []Identifier
+			for x := r.Intn(3); x >= 0; x-- {
+				d = append(d, id(r.Intn(length)))
+			}
+			c = append(c, Dependency(d...))
+		}
+		if r.Float64() < .3 {
+			c = append(c, Conflict(id(r.Intn(length))))
+		}
+		if r.Float64() < .1 {
+			c = append(c, AtMost(1, id(r.Intn(length)), id(r.Intn(length)), id(r.Intn(length))))
+		}
+		result[i] = variable(id(i), c...)
+	}
+	return result
+}
+
+func TestMinimalConflicts(t *testing.T) {
+	var checked int
+	for seed := int64(0); seed < 64; seed++ {
+		input := unsatisfiableInput(seed)
+		s, err := New(WithInput(input), WithMinimalConflicts())
+		require.NoError(t, err)
+
+		_, err = s.Solve(context.Background())
+		var ns NotSatisfiable
+		if !errors.As(err, &ns) {
+			continue
+		}
+		checked++
+
+		// Map the reported constraints back to their
+		// literals in a fresh solver, and check that the set
+		// is unsatisfiable but every proper subset obtained
+		// by removing one constraint is satisfiable.
+		v, err := New(WithInput(input))
+		require.NoError(t, err)
+		fresh := v.(*solver)
+		fresh.prepare()
+		index := make(map[string]z.Lit)
+		for m, a := range fresh.litMap.constraints {
+			index[a.String()] = m
+		}
+		var core []z.Lit
+		for _, a := range ns {
+			m, ok := index[a.String()]
+			require.True(t, ok, "unknown constraint %q", a)
+			core = append(core, m)
+		}
+
+		assert.Equal(t, unsatisfiable, fresh.check(context.Background(), core), "seed %d: %s", seed, ns)
+		for i := range core {
+			var rest []z.Lit
+			rest = append(rest, core[:i]...)
+			rest = append(rest, core[i+1:]...)
+			assert.Equal(t, satisfiable, fresh.check(context.Background(), rest), "seed %d: %s is not necessary in %s", seed, ns[i], ns)
+		}
+	}
+	assert.NotZero(t, checked)
+}
+
+func TestMinimalConflictsCancelled(t *testing.T) {
+	s, err := New(WithInput([]Variable{
+		variable("a", Mandatory(), Prohibited()),
+	}), WithMinimalConflicts())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = s.Solve(ctx)
+
+	// The conflict is found by propagation alone, before any
+	// cancellable work, and is reported unminimized.
+	var ns NotSatisfiable
+	assert.True(t, errors.As(err, &ns))
+	assert.Len(t, ns, 2)
+}
@@ -0,0 +1,110 @@
+package solver
+
+import (
+	"context"
+
+	"github.com/go-air/gini/z"
+)
+
+// Correction describes a minimal set of applied constraints whose
+// removal makes a solution possible.
+type Correction struct {
+	// Relaxed contains the applied constraints to be removed.
+	Relaxed []AppliedConstraint
+	// Result describes the solution found once the constraints
+	// in Relaxed have been removed.
+	Result Result
+}
+
+// Corrections returns up to limit minimal correction sets, or all of
+// them if limit is not positive, in order of increasing size. Only
+// applied constraints for which relaxable returns true are considered
+// for removal; if relaxable is nil, every constraint is. If the
+// problem has a solution, the only Correction returned relaxes
+// nothing. If the provided Context is done before all requested
+// corrections are found, those found so far are returned together
+// with ErrIncomplete.
+func (s *solver) Corrections(ctx context.Context, limit int, relaxable func(AppliedConstraint) bool) (result []Correction, err error) {
+	defer func() {
+		// This likely indicates a bug, so discard whatever
+		// return values were produced.
+		if derr := s.litMap.Error(); derr != nil {
+			result = nil
+			err = derr
+		}
+	}()
+
+	s.prepare()
+
+	var soft, hard []z.Lit
+	for _, m := range s.litMap.ConstraintLits() {
+		if relaxable == nil || relaxable(s.litMap.ConstraintOf(m)) {
+			soft = append(soft, m)
+		} else {
+			hard = append(hard, m)
+		}
+	}
+
+	// Anchors remain assumed unless every constraint that makes
+	// them an anchor may be relaxed.
+	s.litMap.Relax(soft)
+	for _, id := range s.litMap.AnchorIdentifiers() {
+		hard = append(hard, s.litMap.LitOf(id))
+	}
+	s.litMap.Relax(nil)
+
+	// Count the relaxed constraints. Structurally identical
+	// circuits share their literals, so the network is only
+	// encoded by the first call for a given set of constraints.
+	dropped := make([]z.Lit, len(soft))
+	for i, m := range soft {
+		dropped[i] = m.Not()
+	}
+	cs := s.litMap.CardinalityConstrainer(s.g, dropped)
+
+	// Each correction found is blocked by assuming that at least
+	// one of its constraints holds, rather than by adding a
+	// clause, so that nothing outlives this call.
+	var blocks []z.Lit
+	for k := 0; k <= cs.N() && (limit <= 0 || len(result) < limit); {
+		s.litMap.AssumeVariables(s.g)
+		s.g.Assume(hard...)
+		s.g.Assume(blocks...)
+		s.g.Assume(cs.Leq(k))
+		switch solve(ctx, s.g) {
+		case unknown:
+			return result, ErrIncomplete
+		case unsatisfiable:
+			k++
+			continue
+		}
+
+		var relaxed []z.Lit
+		for _, m := range soft {
+			if !s.g.Value(m) {
+				relaxed = append(relaxed, m)
+			}
+		}
+
+		s.litMap.Relax(relaxed)
+		solution, err := s.solve(ctx)
+		s.litMap.Relax(nil)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, Correction{
+			Relaxed: s.litMap.ConstraintsOf(relaxed),
+			Result:  solution,
+		})
+		if len(relaxed) == 0 {
+			break
+		}
+
+		// Exclude this correction and any that contain it.
+		block := s.litMap.c.Ors(relaxed...)
+		s.litMap.Encode(s.g, block)
+		blocks = append(blocks, block)
+	}
+
+	return result, nil
+}
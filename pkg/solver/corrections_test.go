@@ -0,0 +1,146 @@
+package solver
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrections(t *testing.T) {
+	type correction struct {
+		Relaxed  []string
+		Selected []Identifier
+	}
+
+	type tc struct {
+		Name        string
+		Variables   []Variable
+		Limit       int
+		Relaxable   func(AppliedConstraint) bool
+		Corrections []correction
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "satisfiable",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+			},
+			Corrections: []correction{
+				{Selected: []Identifier{"a"}},
+			},
+		},
+		{
+			Name: "mandatory and prohibited",
+			Variables: []Variable{
+				variable("a", Mandatory(), Prohibited()),
+			},
+			Corrections: []correction{
+				{Relaxed: []string{"a is mandatory"}},
+				{Relaxed: []string{"a is prohibited"}, Selected: []Identifier{"a"}},
+			},
+		},
+		{
+			Name: "only relaxable constraints are suggested",
+			Variables: []Variable{
+				variable("a", Mandatory(), Prohibited()),
+			},
+			Relaxable: func(a AppliedConstraint) bool {
+				return a.Constraint == Prohibited()
+			},
+			Corrections: []correction{
+				{Relaxed: []string{"a is prohibited"}, Selected: []Identifier{"a"}},
+			},
+		},
+		{
+			Name: "no relaxable constraints",
+			Variables: []Variable{
+				variable("a", Mandatory(), Prohibited()),
+			},
+			Relaxable: func(AppliedConstraint) bool {
+				return false
+			},
+		},
+		{
+			Name: "smaller corrections first",
+			Variables: []Variable{
+				variable("a", Mandatory(), Prohibited(), Dependency("x")),
+				variable("x", Prohibited()),
+			},
+			Relaxable: func(a AppliedConstraint) bool {
+				return a.Variable.Identifier() == "a"
+			},
+			Corrections: []correction{
+				{Relaxed: []string{"a is mandatory"}},
+				{Relaxed: []string{"a is prohibited", "a requires at least one of x"}, Selected: []Identifier{"a"}},
+			},
+		},
+		{
+			Name: "limit",
+			Variables: []Variable{
+				variable("a", Mandatory(), Prohibited(), Dependency("x")),
+				variable("x", Prohibited()),
+			},
+			Relaxable: func(a AppliedConstraint) bool {
+				return a.Variable.Identifier() == "a"
+			},
+			Limit: 1,
+			Corrections: []correction{
+				{Relaxed: []string{"a is mandatory"}},
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s, err := New(WithInput(tt.Variables))
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			corrections, err := s.Corrections(context.TODO(), tt.Limit, tt.Relaxable)
+			assert.NoError(err)
+
+			var actual []correction
+			for _, c := range corrections {
+				var relaxed []string
+				for _, a := range c.Relaxed {
+					relaxed = append(relaxed, a.String())
+				}
+				sort.Strings(relaxed)
+				var selected []Identifier
+				for _, v := range c.Result.Selected {
+					selected = append(selected, v.Identifier())
+				}
+				actual = append(actual, correction{Relaxed: relaxed, Selected: selected})
+			}
+			assert.True(sort.SliceIsSorted(actual, func(i, j int) bool {
+				return len(actual[i].Relaxed) < len(actual[j].Relaxed)
+			}))
+			// Corrections of the same size may be found in any order.
+			sort.SliceStable(actual, func(i, j int) bool {
+				if len(actual[i].Relaxed) != len(actual[j].Relaxed) {
+					return len(actual[i].Relaxed) < len(actual[j].Relaxed)
+				}
+				return strings.Join(actual[i].Relaxed, ", ") < strings.Join(actual[j].Relaxed, ", ")
+			})
+			assert.Equal(tt.Corrections, actual)
+
+			// The problem itself is left unchanged.
+			before, err := s.SolveResult(context.TODO())
+			assert.Equal(len(tt.Corrections) == 1 && tt.Corrections[0].Relaxed == nil, err == nil)
+
+			// Calling Corrections again finds as many corrections
+			// without adding to the encoding of the problem.
+			again, err := s.Corrections(context.TODO(), tt.Limit, tt.Relaxable)
+			assert.NoError(err)
+			assert.Len(again, len(corrections))
+			after, _ := s.SolveResult(context.TODO())
+			assert.Equal(before.Statistics.Variables, after.Statistics.Variables)
+			assert.Equal(before.Statistics.Clauses, after.Statistics.Clauses)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package solver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/go-air/gini/z"
+)
+
+// clauses is an inter.Adder that records the clauses added to it.
+type clauses struct {
+	clauses [][]z.Lit
+	clause  []z.Lit
+}
+
+func (c *clauses) Add(m z.Lit) {
+	if m == z.LitNull {
+		c.clauses = append(c.clauses, c.clause)
+		c.clause = nil
+		return
+	}
+	c.clause = append(c.clause, m)
+}
+
+// WriteDIMACS writes the CNF encoding of the problem described by the
+// given Options, as it is taught to the underlying SAT solver, to w
+// in DIMACS format. Objectives are not included.
+//
+// Comment lines preceding the header relate the encoding to the
+// problem. Each line of the form
+//
+//	c variable <var> <identifier>
+//
+// gives the variable number of an input Variable, and each line of
+// the form
+//
+//	c constraint <lit> <description>
+//
+// gives the literal that is true exactly when an applied constraint
+// holds, in input order followed by global Constraints. The literals
+// that Solve assumes to be true, which are those of the anchors and
+// of every constraint that is not soft, are listed on a single line
+// terminated by zero:
+//
+//	c assume <lit>... 0
+//
+// Adding each assumed literal as a unit clause yields a formula that
+// is satisfiable exactly when the problem has a solution.
+func WriteDIMACS(w io.Writer, options ...Option) error {
+	s, err := New(options...)
+	if err != nil {
+		return err
+	}
+	d := s.(*solver).litMap
+
+	var cnf clauses
+	d.c.ToCnf(&cnf)
+
+	b := bufio.NewWriter(w)
+	for _, variable := range d.inorder {
+		fmt.Fprintf(b, "c variable %d %s\n", d.LitOf(variable.Identifier()).Dimacs(), variable.Identifier())
+	}
+	describe := func(ms []z.Lit, constraints []Constraint, subject Identifier) {
+		for i, m := range ms {
+			if m == z.LitNull {
+				continue
+			}
+			fmt.Fprintf(b, "c constraint %d %s\n", m.Dimacs(), constraints[i].String(subject))
+		}
+	}
+	for _, variable := range d.inorder {
+		describe(d.applied[variable.Identifier()], variable.Constraints(), variable.Identifier())
+	}
+	describe(d.globalLits, d.globals, "")
+
+	fmt.Fprint(b, "c assume")
+	assumed := make(map[z.Lit]struct{})
+	for _, id := range d.AnchorIdentifiers() {
+		assumed[d.LitOf(id)] = struct{}{}
+	}
+	for _, m := range d.ConstraintLits() {
+		assumed[m] = struct{}{}
+	}
+	ms := make([]z.Lit, 0, len(assumed))
+	for m := range assumed {
+		ms = append(ms, m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i] < ms[j] })
+	for _, m := range ms {
+		fmt.Fprintf(b, " %d", m.Dimacs())
+	}
+	fmt.Fprint(b, " 0\n")
+
+	if err := d.Error(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(b, "p cnf %d %d\n", d.c.Len()-1, len(cnf.clauses))
+	for _, clause := range cnf.clauses {
+		for _, m := range clause {
+			fmt.Fprintf(b, "%d ", m.Dimacs())
+		}
+		fmt.Fprint(b, "0\n")
+	}
+	return b.Flush()
+}
@@ -0,0 +1,107 @@
+package solver
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-air/gini"
+	"github.com/go-air/gini/z"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDIMACS(t *testing.T) {
+	var b bytes.Buffer
+	err := WriteDIMACS(&b,
+		WithInput([]Variable{
+			variable("a", Mandatory(), Dependency("b")),
+			variable("b", Prohibited()),
+		}),
+		WithGlobalConstraints(AtMost(1, "a", "b")),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, `c variable 2 a
+c variable 3 b
+c constraint 2 a is mandatory
+c constraint -4 a requires at least one of b
+c constraint -3 b is prohibited
+c constraint -5 at most 1 of a, b are permitted
+c assume 2 -3 -4 -5 0
+p cnf 6 10
+1 0
+-4 2 0
+-4 -3 0
+4 -2 3 0
+-5 2 0
+-5 3 0
+5 -2 -3 0
+-6 -2 0
+-6 -3 0
+6 2 3 0
+`, b.String())
+}
+
+func TestWriteDIMACSSatisfiable(t *testing.T) {
+	for _, tt := range []struct {
+		Name        string
+		Variables   []Variable
+		Satisfiable bool
+	}{
+		{
+			Name: "satisfiable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("b", "c")),
+				variable("b", Prohibited()),
+				variable("c", AtLeast(1, "d")),
+				variable("d"),
+			},
+			Satisfiable: true,
+		},
+		{
+			Name: "not satisfiable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("b", "c")),
+				variable("b", Prohibited()),
+				variable("c", Conflict("a")),
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			var b bytes.Buffer
+			require.NoError(t, WriteDIMACS(&b, WithInput(tt.Variables)))
+
+			// Teach the clauses and assumptions to a fresh
+			// solver, as an external tool would.
+			g := gini.New()
+			scanner := bufio.NewScanner(&b)
+			for scanner.Scan() {
+				line := scanner.Text()
+				fields := strings.Fields(line)
+				assume := strings.HasPrefix(line, "c assume ")
+				switch {
+				case assume:
+					fields = fields[2:]
+				case strings.HasPrefix(line, "c "), strings.HasPrefix(line, "p "):
+					continue
+				}
+				for _, field := range fields {
+					n, err := strconv.Atoi(field)
+					require.NoError(t, err)
+					switch {
+					case n != 0:
+						g.Add(z.Dimacs2Lit(n))
+						if assume {
+							g.Add(z.LitNull)
+						}
+					case !assume:
+						g.Add(z.LitNull)
+					}
+				}
+			}
+			assert.Equal(t, tt.Satisfiable, g.Solve() == satisfiable)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+// Package solver implements a general-purpose solver for boolean
+// constraint satisfiability problems.
+//
+// Problems are expressed as a slice of Variables, each of which
+// carries the Constraints that apply to it, and are solved by a
+// Solver constructed with New:
+//
+//	s, err := solver.New(solver.WithInput(variables))
+//	if err != nil {
+//		return err
+//	}
+//	selected, err := s.Solve(ctx)
+//
+// The kind and arguments of any Constraint can be recovered with
+// Inspect, so that problems can be analyzed without parsing the
+// descriptions returned by Constraint.String.
+//
+// The exported API of this package is stable: exported identifiers
+// are not removed or changed in incompatible ways without a major
+// version change of the module, and the semantics of the built-in
+// Constraints (Mandatory, Prohibited, Dependency, Conflict, AtMost,
+// AtLeast, Exactly and WeightedAtMost) and of the search order used
+// by Solve are preserved across releases. Interfaces that are only
+// implemented by this package, such as Solver, may gain new methods.
+package solver
@@ -0,0 +1,183 @@
+package solver
+
+// ConstraintExplanation is a structured description of a Constraint
+// as applied to a particular Variable.
+type ConstraintExplanation struct {
+	// Kind identifies the type of the Constraint.
+	Kind ConstraintKind `json:"kind"`
+	// Subject identifies the Variable the Constraint applies to,
+	// and is empty for global Constraints.
+	Subject Identifier `json:"subject,omitempty"`
+	// Identifiers contains the Identifiers of the other Variables
+	// referenced by the Constraint, including those referenced by
+	// its operands. For Constraints not provided by this package,
+	// it contains the result of the Constraint's Order method.
+	Identifiers []Identifier `json:"identifiers,omitempty"`
+	// Coefficients contains the coefficient of each Variable
+	// identified by Identifiers in a WeightedAtMost Constraint,
+	// summed over the Terms that identify it.
+	Coefficients []int `json:"coefficients,omitempty"`
+	// Min and Max, when present, bound the number of Variables
+	// identified by Identifiers that the Constraint permits to
+	// appear in a solution. For WeightedAtMost, Max bounds the
+	// sum of the Coefficients of those Variables instead.
+	Min *int `json:"min,omitempty"`
+	Max *int `json:"max,omitempty"`
+	// Weight is the cost of violating a soft Constraint, and is
+	// zero for all others.
+	Weight int `json:"weight,omitempty"`
+	// Operands contains the explanations of the Constraints
+	// combined by And, Or, Not and Implies, in argument order.
+	Operands []ConstraintExplanation `json:"operands,omitempty"`
+	// Message is the human-readable description of the
+	// Constraint.
+	Message string `json:"message"`
+}
+
+// ConflictGroup is a set of constraints that interact with each
+// other by referencing, directly or through others in the group, the
+// same Variables.
+type ConflictGroup struct {
+	Constraints []ConstraintExplanation `json:"constraints"`
+}
+
+// Explanation is a structured description of a NotSatisfiable error.
+type Explanation struct {
+	// Groups partitions the constraints of the error, in the order
+	// they appear in the error, into sets of interacting
+	// constraints. Groups are ordered by their first constraint.
+	Groups []ConflictGroup `json:"groups"`
+}
+
+// Explain returns a structured description of the receiver.
+func (a AppliedConstraint) Explain() ConstraintExplanation {
+	var subject Identifier
+	if a.Variable != nil {
+		subject = a.Variable.Identifier()
+	}
+	return explain(a.Constraint, subject)
+}
+
+// Explain returns a structured description of the receiver that
+// groups its constraints by the Variables they reference.
+func (e NotSatisfiable) Explain() Explanation {
+	explanations := make([]ConstraintExplanation, len(e))
+	for i, a := range e {
+		explanations[i] = a.Explain()
+	}
+
+	// Join constraints that reference a common Variable, using
+	// the index of the first constraint to reference each.
+	parent := make([]int, len(e))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	first := make(map[Identifier]int)
+	for i, x := range explanations {
+		ids := x.Identifiers
+		if x.Subject != "" {
+			ids = append([]Identifier{x.Subject}, ids...)
+		}
+		for _, id := range ids {
+			j, ok := first[id]
+			if !ok {
+				first[id] = i
+				continue
+			}
+			if ri, rj := find(i), find(j); ri != rj {
+				if ri < rj {
+					parent[rj] = ri
+				} else {
+					parent[ri] = rj
+				}
+			}
+		}
+	}
+
+	result := Explanation{Groups: []ConflictGroup{}}
+	groups := make(map[int]int)
+	for i, x := range explanations {
+		root := find(i)
+		g, ok := groups[root]
+		if !ok {
+			g = len(result.Groups)
+			groups[root] = g
+			result.Groups = append(result.Groups, ConflictGroup{})
+		}
+		result.Groups[g].Constraints = append(result.Groups[g].Constraints, x)
+	}
+	return result
+}
+
+// explain returns a structured description of the provided Constraint
+// as applied to subject.
+func explain(constraint Constraint, subject Identifier) ConstraintExplanation {
+	info := Inspect(constraint)
+	if info.Kind == KindSoft {
+		x := explain(info.Operands[0], subject)
+		x.Weight = info.Weight
+		x.Message = constraint.String(subject)
+		return x
+	}
+
+	x := ConstraintExplanation{
+		Kind:        info.Kind,
+		Subject:     subject,
+		Identifiers: appendUnique(nil, info.Identifiers...),
+		Message:     constraint.String(subject),
+	}
+	if info.Kind == KindWeightedAtMost {
+		x.Coefficients = make([]int, len(x.Identifiers))
+		for i, id := range info.Identifiers {
+			for j, each := range x.Identifiers {
+				if each == id {
+					x.Coefficients[j] += info.Coefficients[i]
+				}
+			}
+		}
+	}
+	for _, each := range info.Operands {
+		o := explain(each, subject)
+		x.Operands = append(x.Operands, o)
+		x.Identifiers = appendUnique(x.Identifiers, o.Identifiers...)
+	}
+
+	min, max := info.N, info.N
+	switch info.Kind {
+	case KindDependency:
+		min = 1
+		x.Min = &min
+	case KindAtMost, KindWeightedAtMost:
+		x.Max = &max
+	case KindAtLeast:
+		x.Min = &min
+	case KindExactly:
+		x.Min, x.Max = &min, &max
+	}
+	return x
+}
+
+// appendUnique appends the Identifiers in ids that are not already
+// present in dst.
+func appendUnique(dst []Identifier, ids ...Identifier) []Identifier {
+	for _, id := range ids {
+		found := false
+		for _, each := range dst {
+			if each == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, id)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,160 @@
+package solver
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraintExplanation(t *testing.T) {
+	one, two, four := 1, 2, 4
+
+	type tc struct {
+		Name     string
+		Applied  AppliedConstraint
+		Expected ConstraintExplanation
+	}
+
+	for _, tt := range []tc{
+		{
+			Name:    "mandatory",
+			Applied: AppliedConstraint{Variable: variable("a"), Constraint: Mandatory()},
+			Expected: ConstraintExplanation{
+				Kind:    KindMandatory,
+				Subject: "a",
+				Message: "a is mandatory",
+			},
+		},
+		{
+			Name:    "dependency",
+			Applied: AppliedConstraint{Variable: variable("a"), Constraint: Dependency("x", "y")},
+			Expected: ConstraintExplanation{
+				Kind:        KindDependency,
+				Subject:     "a",
+				Identifiers: []Identifier{"x", "y"},
+				Min:         &one,
+				Message:     "a requires at least one of x, y",
+			},
+		},
+		{
+			Name:    "global at most",
+			Applied: AppliedConstraint{Constraint: AtMost(1, "x", "y")},
+			Expected: ConstraintExplanation{
+				Kind:        KindAtMost,
+				Identifiers: []Identifier{"x", "y"},
+				Max:         &one,
+				Message:     "at most 1 of x, y are permitted",
+			},
+		},
+		{
+			Name:    "exactly",
+			Applied: AppliedConstraint{Variable: variable("a"), Constraint: Exactly(2, "x", "y", "z")},
+			Expected: ConstraintExplanation{
+				Kind:        KindExactly,
+				Subject:     "a",
+				Identifiers: []Identifier{"x", "y", "z"},
+				Min:         &two,
+				Max:         &two,
+				Message:     "a requires exactly 2 of x, y, z",
+			},
+		},
+		{
+			Name:    "weighted at most",
+			Applied: AppliedConstraint{Variable: variable("a"), Constraint: WeightedAtMost(4, Term{"x", 3}, Term{"y", -1}, Term{"x", 2})},
+			Expected: ConstraintExplanation{
+				Kind:         KindWeightedAtMost,
+				Subject:      "a",
+				Identifiers:  []Identifier{"x", "y"},
+				Coefficients: []int{5, -1},
+				Max:          &four,
+				Message:      "a permits a weighted sum of at most 4 of 3*x, -1*y, 2*x",
+			},
+		},
+		{
+			Name:    "soft",
+			Applied: AppliedConstraint{Variable: variable("a"), Constraint: Soft(3, Conflict("b"))},
+			Expected: ConstraintExplanation{
+				Kind:        KindConflict,
+				Subject:     "a",
+				Identifiers: []Identifier{"b"},
+				Weight:      3,
+				Message:     "a conflicts with b (weight 3)",
+			},
+		},
+		{
+			Name:    "combinator",
+			Applied: AppliedConstraint{Variable: variable("a"), Constraint: Implies(Selected("x"), Not(Selected("y")))},
+			Expected: ConstraintExplanation{
+				Kind:        KindImplies,
+				Subject:     "a",
+				Identifiers: []Identifier{"x", "y"},
+				Operands: []ConstraintExplanation{
+					{
+						Kind:        KindSelected,
+						Subject:     "a",
+						Identifiers: []Identifier{"x"},
+						Message:     "x is selected",
+					},
+					{
+						Kind:        KindNot,
+						Subject:     "a",
+						Identifiers: []Identifier{"y"},
+						Operands: []ConstraintExplanation{
+							{
+								Kind:        KindSelected,
+								Subject:     "a",
+								Identifiers: []Identifier{"y"},
+								Message:     "y is selected",
+							},
+						},
+						Message: "not (y is selected)",
+					},
+				},
+				Message: "(if x is selected then not (y is selected))",
+			},
+		},
+		{
+			Name:    "custom",
+			Applied: AppliedConstraint{Variable: variable("a"), Constraint: requiresAll{"x", "y"}},
+			Expected: ConstraintExplanation{
+				Kind:    KindCustom,
+				Subject: "a",
+				Message: requiresAll{"x", "y"}.String("a"),
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, tt.Applied.Explain())
+		})
+	}
+}
+
+func TestNotSatisfiableExplain(t *testing.T) {
+	assert := assert.New(t)
+
+	a := variable("a", Mandatory(), Conflict("b"))
+	b := variable("b", Mandatory())
+	c := variable("c", Mandatory(), Prohibited())
+	ns := NotSatisfiable{
+		{Variable: a, Constraint: Mandatory()},
+		{Variable: c, Constraint: Mandatory()},
+		{Variable: a, Constraint: Conflict("b")},
+		{Variable: c, Constraint: Prohibited()},
+		{Variable: b, Constraint: Mandatory()},
+	}
+
+	actual, err := json.Marshal(ns.Explain())
+	assert.NoError(err)
+	assert.JSONEq(`{"groups": [
+		{"constraints": [
+			{"kind": "mandatory", "subject": "a", "message": "a is mandatory"},
+			{"kind": "conflict", "subject": "a", "identifiers": ["b"], "message": "a conflicts with b"},
+			{"kind": "mandatory", "subject": "b", "message": "b is mandatory"}
+		]},
+		{"constraints": [
+			{"kind": "mandatory", "subject": "c", "message": "c is mandatory"},
+			{"kind": "prohibited", "subject": "c", "message": "c is prohibited"}
+		]}
+	]}`, string(actual))
+}
@@ -0,0 +1,40 @@
+package solver
+
+// AddVariables adds the provided Variables to the problem, replacing
+// any existing Variables with the same Identifiers, so that the next
+// call to Solve takes them into account. Replaced Variables keep their
+// position in the search order, and new Variables follow all others.
+//
+// The existing encoding and any clauses learned by the underlying
+// solver are kept, so that solving a modified problem is usually
+// cheaper than constructing a new Solver for it. Once a problem has
+// been modified, Constraints that refer to Variables that are not
+// part of it treat those Variables as never selected, rather than
+// causing Solve to fail.
+func (s *solver) AddVariables(variables ...Variable) error {
+	if err := s.litMap.AddVariables(variables); err != nil {
+		return err
+	}
+	s.prepared = false
+	return nil
+}
+
+// RemoveVariables removes the Variables with the provided Identifiers,
+// together with their Constraints, from the problem. Constraints of
+// other Variables that refer to them treat them as never selected.
+func (s *solver) RemoveVariables(ids ...Identifier) error {
+	if err := s.litMap.RemoveVariables(ids); err != nil {
+		return err
+	}
+	s.prepared = false
+	return nil
+}
+
+// SetGlobalConstraints replaces the global Constraints of the problem,
+// including those added by WithGlobalConstraints, with the provided
+// Constraints.
+func (s *solver) SetGlobalConstraints(constraints ...Constraint) {
+	s.globals = append([]Constraint(nil), constraints...)
+	s.litMap.SetGlobalConstraints(s.globals)
+	s.prepared = false
+}
@@ -0,0 +1,340 @@
+package solver
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncremental(t *testing.T) {
+	type tc struct {
+		Name       string
+		Variables  []Variable
+		Globals    []Constraint
+		Objectives []Objective
+		Change     func(Solver) error
+		Installed  []Identifier
+		Error      string
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "add variable",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+			},
+			Change: func(s Solver) error {
+				return s.AddVariables(variable("b", Mandatory()))
+			},
+			Installed: []Identifier{"a", "b"},
+		},
+		{
+			Name: "add referenced variable",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+			},
+			Change: func(s Solver) error {
+				if err := s.AddVariables(variable("b", Mandatory(), Dependency("c", "d"))); err != nil {
+					return err
+				}
+				return s.AddVariables(variable("d"))
+			},
+			Installed: []Identifier{"a", "b", "d"},
+		},
+		{
+			Name: "replace variable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x")),
+				variable("x"),
+				variable("y"),
+			},
+			Change: func(s Solver) error {
+				return s.AddVariables(variable("a", Mandatory(), Dependency("y")))
+			},
+			Installed: []Identifier{"a", "y"},
+		},
+		{
+			Name: "replaced variable keeps its preference",
+			Variables: []Variable{
+				variable("a", Mandatory(), AtLeast(1, "x", "y")),
+				variable("x", Prohibited()),
+				variable("y"),
+			},
+			Change: func(s Solver) error {
+				return s.AddVariables(variable("x"))
+			},
+			Installed: []Identifier{"a", "x"},
+		},
+		{
+			Name: "remove variable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Change: func(s Solver) error {
+				return s.RemoveVariables("x")
+			},
+			Installed: []Identifier{"a", "y"},
+		},
+		{
+			Name: "remove only candidate",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x")),
+				variable("x"),
+			},
+			Change: func(s Solver) error {
+				return s.RemoveVariables("x")
+			},
+			Error: "constraints not satisfiable: a is mandatory, a requires at least one of x",
+		},
+		{
+			Name: "remove and add again",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Change: func(s Solver) error {
+				if err := s.RemoveVariables("x"); err != nil {
+					return err
+				}
+				return s.AddVariables(variable("x"))
+			},
+			Installed: []Identifier{"a", "x"},
+		},
+		{
+			Name: "remove unknown variable",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+			},
+			Change: func(s Solver) error {
+				return s.RemoveVariables("a", "missing")
+			},
+			Error: `variable "missing" not provided`,
+		},
+		{
+			Name: "add duplicate variables",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+			},
+			Change: func(s Solver) error {
+				return s.AddVariables(variable("b"), variable("b"))
+			},
+			Error: `duplicate identifier "b" in input`,
+		},
+		{
+			Name: "add variable with empty identifier",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+			},
+			Change: func(s Solver) error {
+				return s.AddVariables(variable(""))
+			},
+			Error: "empty identifier in input",
+		},
+		{
+			Name: "set global constraints",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Globals: []Constraint{Prohibited()},
+			Change: func(s Solver) error {
+				s.SetGlobalConstraints(Dependency("y"))
+				return nil
+			},
+			Installed: []Identifier{"a", "y"},
+		},
+		{
+			Name: "remove variable sharing a constraint",
+			Variables: []Variable{
+				variable("p", AtMost(1, "a", "b")),
+				variable("q", Mandatory(), AtMost(1, "a", "b"), Dependency("a"), Dependency("b")),
+				variable("a"),
+				variable("b"),
+			},
+			Change: func(s Solver) error {
+				return s.RemoveVariables("p")
+			},
+			Error: "constraints not satisfiable: q is mandatory, q permits at most 1 of a, b, q requires at least one of a, q requires at least one of b",
+		},
+		{
+			Name: "replace variable sharing a constraint",
+			Variables: []Variable{
+				variable("p", AtMost(1, "a", "b")),
+				variable("q", Mandatory(), AtMost(1, "a", "b"), Dependency("a"), Dependency("b")),
+				variable("a"),
+				variable("b"),
+			},
+			Change: func(s Solver) error {
+				return s.AddVariables(variable("p"))
+			},
+			Error: "constraints not satisfiable: q is mandatory, q permits at most 1 of a, b, q requires at least one of a, q requires at least one of b",
+		},
+		{
+			Name: "set global constraints sharing a constraint",
+			Variables: []Variable{
+				variable("q", Mandatory(), AtMost(1, "a", "b"), Dependency("a"), Dependency("b")),
+				variable("a"),
+				variable("b"),
+			},
+			Globals: []Constraint{AtMost(1, "a", "b")},
+			Change: func(s Solver) error {
+				s.SetGlobalConstraints()
+				return nil
+			},
+			Error: "constraints not satisfiable: q is mandatory, q permits at most 1 of a, b, q requires at least one of a, q requires at least one of b",
+		},
+		{
+			Name: "objectives follow changes",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Objectives: []Objective{MinimizeSize()},
+			Change: func(s Solver) error {
+				return s.AddVariables(variable("a", Mandatory(), Dependency("x", "y"), Dependency("y")))
+			},
+			Installed: []Identifier{"a", "y"},
+		},
+		{
+			Name: "soft constraints follow changes",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y"), Soft(1, Conflict("x"))),
+				variable("x"),
+				variable("y"),
+			},
+			Change: func(s Solver) error {
+				return s.AddVariables(variable("a", Mandatory(), Dependency("x", "y")))
+			},
+			Installed: []Identifier{"a", "x"},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s, err := New(WithInput(tt.Variables), WithGlobalConstraints(tt.Globals...), WithObjectives(tt.Objectives...), WithMinimalConflicts())
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			// Solve once before changing the problem, so that
+			// the changes are made to an encoded problem.
+			_, _ = s.Solve(context.TODO())
+
+			err = tt.Change(s)
+			if err == nil {
+				var installed []Variable
+				installed, err = s.Solve(context.TODO())
+				var ids []Identifier
+				for _, variable := range installed {
+					ids = append(ids, variable.Identifier())
+				}
+				assert.Equal(tt.Installed, ids)
+			}
+			if tt.Error != "" {
+				if ns, ok := err.(NotSatisfiable); ok {
+					sortNotSatisfiable(ns)
+				}
+				assert.EqualError(err, tt.Error)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
+
+func TestIncrementalDifferential(t *testing.T) {
+	const (
+		iterations = 200
+		changes    = 5
+		length     = 10
+	)
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < iterations; i++ {
+		variables, globals := randomInput(rng, length)
+		alternatives, alternativeGlobals := randomInput(rng, length)
+
+		s, err := New(WithInput(variables), WithGlobalConstraints(globals...))
+		require.NoError(t, err)
+		_, _ = s.Solve(context.Background())
+
+		// The Variables in the problem, in search order.
+		current := append([]Variable(nil), variables...)
+		for j := 0; j < changes; j++ {
+			k := rng.Intn(length)
+			position := -1
+			for x, variable := range current {
+				if variable.Identifier() == variables[k].Identifier() {
+					position = x
+				}
+			}
+			switch {
+			case rng.Float64() < .2:
+				globals = alternativeGlobals
+				s.SetGlobalConstraints(globals...)
+			case position < 0:
+				// Added Variables follow all others.
+				current = append(current, variables[k])
+				require.NoError(t, s.AddVariables(variables[k]))
+			case rng.Float64() < .5:
+				current = append(current[:position:position], current[position+1:]...)
+				require.NoError(t, s.RemoveVariables(variables[k].Identifier()))
+			default:
+				current[position] = alternatives[k]
+				require.NoError(t, s.AddVariables(alternatives[k]))
+			}
+
+			expected := newReference(current, globals).Solve()
+			installed, err := s.Solve(context.Background())
+			if expected == nil {
+				assert.IsType(t, NotSatisfiable{}, err, "iteration %d, change %d", i, j)
+				continue
+			}
+			require.NoError(t, err, "iteration %d, change %d", i, j)
+
+			ids := []Identifier{}
+			for _, variable := range installed {
+				ids = append(ids, variable.Identifier())
+			}
+			assert.Contains(t, expected, ids, "iteration %d, change %d: input %#v with globals %v", i, j, current, globals)
+		}
+	}
+}
+
+func TestIncrementalEncodingSize(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := New(
+		WithInput([]Variable{
+			variable("a", Mandatory(), Dependency("x", "y"), Soft(2, Conflict("x"))),
+			variable("x"),
+			variable("y", Soft(1, Prohibited())),
+		}),
+		WithObjectives(MinimizeSize()),
+		WithPreviousSolution([]Identifier{"a", "x"}),
+	)
+	require.NoError(t, err)
+
+	// Replacing a Variable with an identical one changes nothing,
+	// so once the replacement has been encoded, doing so again
+	// leaves the encoding of the problem the same size.
+	var sizes [][2]int
+	for i := 0; i < 4; i++ {
+		require.NoError(t, s.AddVariables(variable("y", Soft(1, Prohibited()))))
+		result, err := s.SolveResult(context.TODO())
+		require.NoError(t, err)
+		assert.Equal([]Identifier{"a", "y"}, identifiersOf(result.Selected))
+		sizes = append(sizes, [2]int{result.Statistics.Variables, result.Statistics.Clauses})
+	}
+	for _, size := range sizes[2:] {
+		assert.Equal(sizes[1], size)
+	}
+}
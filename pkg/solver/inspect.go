@@ -0,0 +1,90 @@
+package solver
+
+// ConstraintKind identifies the type of a Constraint.
+type ConstraintKind string
+
+const (
+	KindMandatory      ConstraintKind = "mandatory"
+	KindProhibited     ConstraintKind = "prohibited"
+	KindDependency     ConstraintKind = "dependency"
+	KindConflict       ConstraintKind = "conflict"
+	KindAtMost         ConstraintKind = "atMost"
+	KindAtLeast        ConstraintKind = "atLeast"
+	KindExactly        ConstraintKind = "exactly"
+	KindWeightedAtMost ConstraintKind = "weightedAtMost"
+	KindSelected       ConstraintKind = "selected"
+	KindAnd            ConstraintKind = "and"
+	KindOr             ConstraintKind = "or"
+	KindNot            ConstraintKind = "not"
+	KindImplies        ConstraintKind = "implies"
+	KindSoft           ConstraintKind = "soft"
+	// KindCustom identifies Constraints that are not provided by
+	// this package.
+	KindCustom ConstraintKind = "custom"
+)
+
+// ConstraintInfo describes the arguments a Constraint was
+// constructed with.
+type ConstraintInfo struct {
+	// Kind identifies the function that constructed the
+	// Constraint, or is KindCustom if it was not provided by
+	// this package.
+	Kind ConstraintKind
+	// Identifiers contains the Identifiers passed to the
+	// constructor, in argument order. For Constraints not
+	// provided by this package, it contains the result of the
+	// Constraint's Order method.
+	Identifiers []Identifier
+	// Coefficients contains the coefficients of the Terms passed
+	// to WeightedAtMost, corresponding to Identifiers.
+	Coefficients []int
+	// N is the count passed to AtMost, AtLeast and Exactly, or the
+	// bound passed to WeightedAtMost.
+	N int
+	// Weight is the weight passed to Soft.
+	Weight int
+	// Operands contains the Constraints passed to And, Or, Not,
+	// Implies and Soft, in argument order.
+	Operands []Constraint
+}
+
+// Inspect returns a description of the provided Constraint. The
+// returned slices must not be modified.
+func Inspect(constraint Constraint) ConstraintInfo {
+	switch c := constraint.(type) {
+	case mandatory:
+		return ConstraintInfo{Kind: KindMandatory}
+	case prohibited:
+		return ConstraintInfo{Kind: KindProhibited}
+	case dependency:
+		return ConstraintInfo{Kind: KindDependency, Identifiers: c}
+	case conflict:
+		return ConstraintInfo{Kind: KindConflict, Identifiers: []Identifier{Identifier(c)}}
+	case leq:
+		return ConstraintInfo{Kind: KindAtMost, Identifiers: c.ids, N: c.n}
+	case geq:
+		return ConstraintInfo{Kind: KindAtLeast, Identifiers: c.ids, N: c.n}
+	case exactly:
+		return ConstraintInfo{Kind: KindExactly, Identifiers: c.ids, N: c.n}
+	case weightedLeq:
+		info := ConstraintInfo{Kind: KindWeightedAtMost, N: c.n}
+		for _, each := range c.terms {
+			info.Identifiers = append(info.Identifiers, each.Identifier)
+			info.Coefficients = append(info.Coefficients, each.Coefficient)
+		}
+		return info
+	case selected:
+		return ConstraintInfo{Kind: KindSelected, Identifiers: []Identifier{Identifier(c)}}
+	case and:
+		return ConstraintInfo{Kind: KindAnd, Operands: c}
+	case or:
+		return ConstraintInfo{Kind: KindOr, Operands: c}
+	case not:
+		return ConstraintInfo{Kind: KindNot, Operands: []Constraint{c.operand}}
+	case implies:
+		return ConstraintInfo{Kind: KindImplies, Operands: []Constraint{c.condition, c.consequence}}
+	case soft:
+		return ConstraintInfo{Kind: KindSoft, Weight: c.weight, Operands: []Constraint{c.constraint}}
+	}
+	return ConstraintInfo{Kind: KindCustom, Identifiers: constraint.Order()}
+}
@@ -0,0 +1,97 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspect(t *testing.T) {
+	type tc struct {
+		Name       string
+		Constraint Constraint
+		Expected   ConstraintInfo
+	}
+
+	for _, tt := range []tc{
+		{
+			Name:       "mandatory",
+			Constraint: Mandatory(),
+			Expected:   ConstraintInfo{Kind: KindMandatory},
+		},
+		{
+			Name:       "prohibited",
+			Constraint: Prohibited(),
+			Expected:   ConstraintInfo{Kind: KindProhibited},
+		},
+		{
+			Name:       "dependency",
+			Constraint: Dependency("x", "y"),
+			Expected:   ConstraintInfo{Kind: KindDependency, Identifiers: []Identifier{"x", "y"}},
+		},
+		{
+			Name:       "conflict",
+			Constraint: Conflict("x"),
+			Expected:   ConstraintInfo{Kind: KindConflict, Identifiers: []Identifier{"x"}},
+		},
+		{
+			Name:       "at most",
+			Constraint: AtMost(1, "x", "y"),
+			Expected:   ConstraintInfo{Kind: KindAtMost, Identifiers: []Identifier{"x", "y"}, N: 1},
+		},
+		{
+			Name:       "at least",
+			Constraint: AtLeast(2, "x", "y"),
+			Expected:   ConstraintInfo{Kind: KindAtLeast, Identifiers: []Identifier{"x", "y"}, N: 2},
+		},
+		{
+			Name:       "exactly",
+			Constraint: Exactly(1, "x"),
+			Expected:   ConstraintInfo{Kind: KindExactly, Identifiers: []Identifier{"x"}, N: 1},
+		},
+		{
+			Name:       "weighted at most",
+			Constraint: WeightedAtMost(4, Term{"x", 3}, Term{"y", -1}),
+			Expected:   ConstraintInfo{Kind: KindWeightedAtMost, Identifiers: []Identifier{"x", "y"}, Coefficients: []int{3, -1}, N: 4},
+		},
+		{
+			Name:       "selected",
+			Constraint: Selected("x"),
+			Expected:   ConstraintInfo{Kind: KindSelected, Identifiers: []Identifier{"x"}},
+		},
+		{
+			Name:       "and",
+			Constraint: And(Mandatory(), Selected("x")),
+			Expected:   ConstraintInfo{Kind: KindAnd, Operands: []Constraint{Mandatory(), Selected("x")}},
+		},
+		{
+			Name:       "or",
+			Constraint: Or(Prohibited()),
+			Expected:   ConstraintInfo{Kind: KindOr, Operands: []Constraint{Prohibited()}},
+		},
+		{
+			Name:       "not",
+			Constraint: Not(Selected("x")),
+			Expected:   ConstraintInfo{Kind: KindNot, Operands: []Constraint{Selected("x")}},
+		},
+		{
+			Name:       "implies",
+			Constraint: Implies(Selected("x"), Selected("y")),
+			Expected:   ConstraintInfo{Kind: KindImplies, Operands: []Constraint{Selected("x"), Selected("y")}},
+		},
+		{
+			Name:       "soft",
+			Constraint: Soft(3, Conflict("x")),
+			Expected:   ConstraintInfo{Kind: KindSoft, Weight: 3, Operands: []Constraint{Conflict("x")}},
+		},
+		{
+			Name:       "custom",
+			Constraint: pinned("x"),
+			Expected:   ConstraintInfo{Kind: KindCustom, Identifiers: pinned("x").Order()},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, Inspect(tt.Constraint))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package solver
+
+import (
+	"github.com/go-air/gini/z"
+)
+
+// Justification explains why a Variable appears in a solution.
+type Justification struct {
+	// Variable is the selected Variable.
+	Variable Variable
+	// Constraint is the applied constraint that led to the
+	// selection of Variable: its anchor constraint if it is an
+	// anchor, or otherwise a constraint of another selected
+	// Variable, or a global Constraint, among whose candidates it
+	// appears. Constraint.Constraint is nil if no such constraint
+	// exists.
+	Constraint AppliedConstraint
+	// Forced is true if Variable is an anchor, the only candidate
+	// of Constraint, or was otherwise required by the constraints,
+	// and false if it was chosen by search in order of preference
+	// from among the candidates of Constraint.
+	Forced bool
+	// Rejected contains the candidates of Constraint that are
+	// preferred over Variable but could not be selected. It is
+	// only populated when Variable was chosen by search.
+	Rejected []Identifier
+}
+
+// Why returns the chain of Justifications that led to the selection
+// of the Variable with the given Identifier, starting from an anchor,
+// a global Constraint or a Variable that was required by the
+// constraints, and ending with the Justification of that Variable.
+// It returns nil if the Variable was not selected.
+func (r Result) Why(id Identifier) []Justification {
+	byID := make(map[Identifier]Justification, len(r.Justifications))
+	for _, j := range r.Justifications {
+		byID[j.Variable.Identifier()] = j
+	}
+
+	var chain []Justification
+	seen := make(map[Identifier]struct{})
+	for {
+		j, ok := byID[id]
+		if !ok {
+			break
+		}
+		if _, ok := seen[id]; ok {
+			break
+		}
+		seen[id] = struct{}{}
+		chain = append([]Justification{j}, chain...)
+		if j.Constraint.Variable == nil || j.Constraint.Variable.Identifier() == id {
+			break
+		}
+		id = j.Constraint.Variable.Identifier()
+	}
+	return chain
+}
+
+// justify returns the Justification of each selected Variable, given
+// the guesses made by search.
+func (s *solver) justify(selected []Variable, guessed map[z.Lit]guess) []Justification {
+	anchors := make(map[Identifier]struct{})
+	for _, id := range s.litMap.AnchorIdentifiers() {
+		anchors[id] = struct{}{}
+	}
+
+	// The first constraint of a selected Variable, or failing
+	// that the first global Constraint, to list a candidate
+	// accounts for any candidate that was not guessed.
+	candidates := make(map[Identifier]AppliedConstraint)
+	note := func(a AppliedConstraint) {
+		for _, id := range a.Constraint.Order() {
+			if _, ok := candidates[id]; !ok {
+				candidates[id] = a
+			}
+		}
+	}
+	for _, variable := range selected {
+		for _, constraint := range s.litMap.ActiveConstraints(variable) {
+			note(AppliedConstraint{Variable: variable, Constraint: constraint})
+		}
+	}
+	for _, constraint := range s.litMap.ActiveGlobalConstraints() {
+		note(AppliedConstraint{Constraint: constraint})
+	}
+
+	result := make([]Justification, len(selected))
+	for i, variable := range selected {
+		id := variable.Identifier()
+		m := s.litMap.LitOf(id)
+		j := Justification{Variable: variable, Forced: true}
+		if _, ok := anchors[id]; ok {
+			j.Constraint = s.litMap.AnchorOf(m)
+		} else if g, ok := guessed[m]; ok {
+			j.Constraint = g.source
+			j.Forced = len(g.candidates) == 1
+			for _, c := range g.candidates[:g.index] {
+				j.Rejected = append(j.Rejected, s.litMap.VariableOf(c).Identifier())
+			}
+		} else if a, ok := candidates[id]; ok && (a.Variable == nil || a.Variable.Identifier() != id) {
+			j.Constraint = a
+		}
+		result[i] = j
+	}
+	return result
+}
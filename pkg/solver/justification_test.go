@@ -0,0 +1,132 @@
+package solver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhy(t *testing.T) {
+	type step struct {
+		ID         Identifier
+		Constraint string
+		Forced     bool
+		Rejected   []Identifier
+	}
+
+	type tc struct {
+		Name        string
+		Variables   []Variable
+		Constraints []Constraint
+		ID          Identifier
+		Chain       []step
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "anchor",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+			},
+			ID: "a",
+			Chain: []step{
+				{ID: "a", Constraint: "a is mandatory", Forced: true},
+			},
+		},
+		{
+			Name: "preferred candidate",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			ID: "x",
+			Chain: []step{
+				{ID: "a", Constraint: "a is mandatory", Forced: true},
+				{ID: "x", Constraint: "a requires at least one of x, y"},
+			},
+		},
+		{
+			Name: "preferred candidate rejected",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x", AtLeast(2, "p", "q", "r")),
+				variable("y"),
+				variable("p"),
+				variable("q"),
+				variable("r"),
+			},
+			Constraints: []Constraint{AtMost(1, "p", "q", "r")},
+			ID:          "y",
+			Chain: []step{
+				{ID: "a", Constraint: "a is mandatory", Forced: true},
+				{ID: "y", Constraint: "a requires at least one of x, y", Rejected: []Identifier{"x"}},
+			},
+		},
+		{
+			Name: "transitive dependency",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("b")),
+				variable("b", Dependency("c")),
+				variable("c"),
+			},
+			ID: "c",
+			Chain: []step{
+				{ID: "a", Constraint: "a is mandatory", Forced: true},
+				{ID: "b", Constraint: "a requires at least one of b", Forced: true},
+				{ID: "c", Constraint: "b requires at least one of c", Forced: true},
+			},
+		},
+		{
+			Name: "required without a choice",
+			Variables: []Variable{
+				variable("a", Mandatory(), Implies(Selected("a"), Selected("z"))),
+				variable("z"),
+			},
+			ID: "z",
+			Chain: []step{
+				{ID: "z", Forced: true},
+			},
+		},
+		{
+			Name: "global constraint",
+			Variables: []Variable{
+				variable("x"),
+				variable("y"),
+			},
+			Constraints: []Constraint{Dependency("x", "y")},
+			ID:          "x",
+			Chain: []step{
+				{ID: "x", Constraint: "at least one of x, y is required"},
+			},
+		},
+		{
+			Name: "not selected",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+				variable("b"),
+			},
+			ID: "b",
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			s, err := New(WithInput(tt.Variables), WithGlobalConstraints(tt.Constraints...))
+			require.NoError(t, err)
+			result, err := s.SolveResult(context.TODO())
+			require.NoError(t, err)
+			require.Len(t, result.Justifications, len(result.Selected))
+
+			var chain []step
+			for _, j := range result.Why(tt.ID) {
+				s := step{ID: j.Variable.Identifier(), Forced: j.Forced, Rejected: j.Rejected}
+				if j.Constraint.Constraint != nil {
+					s.Constraint = j.Constraint.String()
+				}
+				chain = append(chain, s)
+			}
+			assert.Equal(t, tt.Chain, chain)
+		})
+	}
+}
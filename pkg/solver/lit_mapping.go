@@ -0,0 +1,624 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-air/gini/inter"
+	"github.com/go-air/gini/logic"
+	"github.com/go-air/gini/z"
+)
+
+// DuplicateIdentifier is returned by New when two input Variables
+// share the same Identifier.
+type DuplicateIdentifier Identifier
+
+func (e DuplicateIdentifier) Error() string {
+	return fmt.Sprintf("duplicate identifier %q in input", Identifier(e))
+}
+
+// ErrEmptyIdentifier is returned by New when an input Variable has
+// the empty Identifier, which stands for the subject of global
+// Constraints.
+var ErrEmptyIdentifier = errors.New("empty identifier in input")
+
+type inconsistentLitMapping []error
+
+func (inconsistentLitMapping) Error() string {
+	return "internal solver failure"
+}
+
+// litMapping performs translation between the input and output types of
+// Solve (Constraints, Variables, etc.) and the variables that
+// appear in the SAT formula.
+type litMapping struct {
+	inorder     []Variable
+	variables   map[z.Lit]Variable
+	lits        map[Identifier]z.Lit
+	constraints map[z.Lit]AppliedConstraint
+	uses        map[z.Lit][]AppliedConstraint // applications sharing each constraint lit
+	soft        []softLit
+	globals     []Constraint
+	applied     map[Identifier][]z.Lit // constraint lits of each variable, in order
+	globalLits  []z.Lit                // constraint lits of globals, in order
+	relaxed     map[z.Lit]struct{}     // constraint lits that are not currently assumed
+	required    []z.Lit                // variable lits that are currently assumed
+	absent      map[z.Lit]struct{}     // variable lits that are referenced but not part of the problem
+	lenient     bool                   // whether LitOf treats unknown identifiers as absent
+	c           *logic.C
+	marks       []int8 // nodes of c that have been taught to the solver
+	last        z.Var  // greatest variable mentioned in a clause by AddConstraints
+	errs        inconsistentLitMapping
+}
+
+var _ LitMapping = &litMapping{}
+
+// softLit associates the literal of a soft constraint application
+// with the weight of violating it.
+type softLit struct {
+	m       z.Lit
+	weight  int
+	applied AppliedConstraint
+}
+
+// newLitMapping returns a new litMapping with its state initialized based on
+// the provided slice of Variables. This includes construction of
+// the translation tables between Variables/Constraints and the
+// inputs to the underlying solver.
+func newLitMapping(variables []Variable) (*litMapping, error) {
+	d := litMapping{
+		inorder:     variables,
+		variables:   make(map[z.Lit]Variable, len(variables)),
+		lits:        make(map[Identifier]z.Lit, len(variables)),
+		constraints: make(map[z.Lit]AppliedConstraint),
+		uses:        make(map[z.Lit][]AppliedConstraint),
+		applied:     make(map[Identifier][]z.Lit, len(variables)),
+		absent:      make(map[z.Lit]struct{}),
+		c:           logic.NewCCap(len(variables)),
+	}
+
+	// First pass to assign lits:
+	for _, variable := range variables {
+		if variable.Identifier() == "" {
+			return nil, ErrEmptyIdentifier
+		}
+		im := d.c.Lit()
+		if _, ok := d.lits[variable.Identifier()]; ok {
+			return nil, DuplicateIdentifier(variable.Identifier())
+		}
+		d.lits[variable.Identifier()] = im
+		d.variables[im] = variable
+	}
+
+	for _, variable := range variables {
+		d.apply(variable)
+	}
+
+	return &d, nil
+}
+
+// apply encodes the Constraints of a single Variable.
+func (d *litMapping) apply(variable Variable) {
+	ms := make([]z.Lit, len(variable.Constraints()))
+	for i, constraint := range variable.Constraints() {
+		ms[i] = d.add(AppliedConstraint{
+			Variable:   variable,
+			Constraint: constraint,
+		}, d, variable.Identifier())
+	}
+	d.applied[variable.Identifier()] = ms
+}
+
+// AddVariables adds the provided Variables to the problem, replacing
+// any Variables with the same Identifiers. Replaced Variables keep
+// their position in input order, and the others are appended to it.
+// From then on, Variables that are referenced by constraints but are
+// not part of the problem are treated as absent rather than as an
+// error.
+func (d *litMapping) AddVariables(variables []Variable) error {
+	index := make(map[Identifier]int, len(d.inorder))
+	for i, variable := range d.inorder {
+		index[variable.Identifier()] = i
+	}
+	seen := make(map[Identifier]struct{}, len(variables))
+	for _, variable := range variables {
+		if variable.Identifier() == "" {
+			return ErrEmptyIdentifier
+		}
+		if _, ok := seen[variable.Identifier()]; ok {
+			return DuplicateIdentifier(variable.Identifier())
+		}
+		seen[variable.Identifier()] = struct{}{}
+	}
+
+	d.lenient = true
+	inorder := make([]Variable, len(d.inorder), len(d.inorder)+len(variables))
+	copy(inorder, d.inorder)
+	for _, variable := range variables {
+		id := variable.Identifier()
+		if i, ok := index[id]; ok {
+			d.forget(d.applied[id], ownedBy(id))
+			inorder[i] = variable
+		} else {
+			inorder = append(inorder, variable)
+		}
+		m, ok := d.lits[id]
+		if !ok {
+			m = d.c.Lit()
+			d.lits[id] = m
+		}
+		delete(d.absent, m)
+		d.variables[m] = variable
+	}
+	d.inorder = inorder
+
+	for _, variable := range variables {
+		d.apply(variable)
+	}
+	return nil
+}
+
+// RemoveVariables removes the Variables with the provided Identifiers
+// from the problem. Constraints that refer to them treat them as
+// absent, which is to say never selected.
+func (d *litMapping) RemoveVariables(ids []Identifier) error {
+	remove := make(map[Identifier]struct{}, len(ids))
+	for _, id := range ids {
+		m, ok := d.lits[id]
+		if _, absent := d.absent[m]; !ok || absent {
+			return fmt.Errorf("variable %q not provided", id)
+		}
+		remove[id] = struct{}{}
+	}
+
+	d.lenient = true
+	inorder := make([]Variable, 0, len(d.inorder))
+	for _, variable := range d.inorder {
+		id := variable.Identifier()
+		if _, ok := remove[id]; !ok {
+			inorder = append(inorder, variable)
+			continue
+		}
+		d.forget(d.applied[id], ownedBy(id))
+		delete(d.applied, id)
+		m := d.lits[id]
+		d.absent[m] = struct{}{}
+		d.variables[m] = absentVariable(id)
+	}
+	d.inorder = inorder
+	return nil
+}
+
+// SetGlobalConstraints replaces the global Constraints added by
+// AddGlobalConstraints with the provided Constraints.
+func (d *litMapping) SetGlobalConstraints(constraints []Constraint) {
+	d.lenient = true
+	d.forget(d.globalLits, ownedBy(""))
+	d.globals, d.globalLits = nil, nil
+	d.AddGlobalConstraints(constraints)
+}
+
+// forget causes the applications of constraints with the provided
+// literals for which owned returns true to no longer be part of the
+// problem. Structurally identical constraints share a literal, which
+// remains assumed as long as any of its applications is not
+// forgotten. The encoding remains in the circuit in any case.
+func (d *litMapping) forget(ms []z.Lit, owned func(AppliedConstraint) bool) {
+	set := make(map[z.Lit]struct{}, len(ms))
+	for _, m := range ms {
+		set[m] = struct{}{}
+	}
+	for m := range set {
+		uses := d.uses[m][:0:0]
+		for _, a := range d.uses[m] {
+			if !owned(a) {
+				uses = append(uses, a)
+			}
+		}
+		if len(uses) == 0 {
+			delete(d.constraints, m)
+			delete(d.uses, m)
+			continue
+		}
+		d.uses[m] = uses
+		d.constraints[m] = uses[len(uses)-1]
+	}
+	soft := d.soft[:0:0]
+	for _, each := range d.soft {
+		if _, ok := set[each.m]; !ok || !owned(each.applied) {
+			soft = append(soft, each)
+		}
+	}
+	d.soft = soft
+}
+
+// ownedBy returns a function that reports whether a constraint is
+// applied to the Variable with the given Identifier, or, if it is
+// empty, whether it is a global Constraint.
+func ownedBy(id Identifier) func(AppliedConstraint) bool {
+	return func(a AppliedConstraint) bool {
+		if a.Variable == nil {
+			return id == ""
+		}
+		return a.Variable.Identifier() == id
+	}
+}
+
+// AddGlobalConstraints encodes Constraints that are not associated
+// with any Variable. Each is applied with the empty Identifier as
+// its subject, standing in for a Variable that appears in every
+// solution.
+func (d *litMapping) AddGlobalConstraints(constraints []Constraint) {
+	for _, constraint := range constraints {
+		d.globals = append(d.globals, constraint)
+		m := d.add(AppliedConstraint{Constraint: constraint}, globalLitMapping{d}, "")
+		d.globalLits = append(d.globalLits, m)
+	}
+}
+
+// add encodes a single constraint application, recording its literal
+// as either a hard constraint to be assumed or a soft constraint to
+// be optimized, and returns the literal.
+func (d *litMapping) add(a AppliedConstraint, lm LitMapping, subject Identifier) z.Lit {
+	m := a.Constraint.Apply(d.c, lm, subject)
+	if m == z.LitNull {
+		// This constraint doesn't have a useful
+		// representation in the SAT inputs.
+		return z.LitNull
+	}
+	if s, ok := a.Constraint.(soft); ok {
+		d.soft = append(d.soft, softLit{m: m, weight: s.weight, applied: a})
+		return m
+	}
+	d.constraints[m] = a
+	d.uses[m] = append(d.uses[m], a)
+	return m
+}
+
+// Relax causes the constraints with the provided literals to no
+// longer be assumed to hold, or to influence search, until the next
+// call to Relax. Passing nil restores all constraints.
+func (d *litMapping) Relax(ms []z.Lit) {
+	d.relaxed = make(map[z.Lit]struct{}, len(ms))
+	for _, m := range ms {
+		d.relaxed[m] = struct{}{}
+	}
+}
+
+// Require causes the provided literals to be assumed, in addition to
+// the constraints, until the next call to Require. The Variables of
+// positive Variable literals are treated as anchors. Passing nil
+// removes all requirements.
+func (d *litMapping) Require(ms []z.Lit) {
+	d.required = ms
+}
+
+// isRequired reports whether the provided literal is currently
+// required.
+func (d *litMapping) isRequired(m z.Lit) bool {
+	for _, each := range d.required {
+		if each == m {
+			return true
+		}
+	}
+	return false
+}
+
+// active returns the subset of constraints whose corresponding
+// literals, at the same index in ms, have not been relaxed.
+func (d *litMapping) active(constraints []Constraint, ms []z.Lit) []Constraint {
+	if len(d.relaxed) == 0 {
+		return constraints
+	}
+	result := make([]Constraint, 0, len(constraints))
+	for i, constraint := range constraints {
+		if i < len(ms) {
+			if _, ok := d.relaxed[ms[i]]; ok {
+				continue
+			}
+		}
+		result = append(result, constraint)
+	}
+	return result
+}
+
+// ActiveConstraints returns the Constraints of the provided Variable
+// that have not been relaxed.
+func (d *litMapping) ActiveConstraints(variable Variable) []Constraint {
+	return d.active(variable.Constraints(), d.applied[variable.Identifier()])
+}
+
+// ActiveGlobalConstraints returns the global Constraints added by
+// AddGlobalConstraints that have not been relaxed, in the order they
+// were added.
+func (d *litMapping) ActiveGlobalConstraints() []Constraint {
+	return d.active(d.globals, d.globalLits)
+}
+
+// LitOf returns the positive literal corresponding to the Variable
+// with the given Identifier.
+func (d *litMapping) LitOf(id Identifier) z.Lit {
+	m, ok := d.lits[id]
+	if ok {
+		return m
+	}
+	if d.lenient {
+		m = d.c.Lit()
+		d.lits[id] = m
+		d.variables[m] = absentVariable(id)
+		d.absent[m] = struct{}{}
+		return m
+	}
+	d.errs = append(d.errs, fmt.Errorf("variable %q referenced but not provided", id))
+	return z.LitNull
+}
+
+// absentVariable stands in for a Variable that is referenced by a
+// constraint but is not part of the problem.
+type absentVariable Identifier
+
+func (v absentVariable) Identifier() Identifier {
+	return Identifier(v)
+}
+
+func (absentVariable) Constraints() []Constraint {
+	return nil
+}
+
+// globalLitMapping is the LitMapping passed to global Constraints. It
+// maps the empty Identifier to a literal that is always true.
+type globalLitMapping struct {
+	*litMapping
+}
+
+func (d globalLitMapping) LitOf(id Identifier) z.Lit {
+	if id == "" {
+		return d.c.T
+	}
+	return d.litMapping.LitOf(id)
+}
+
+// Identifiers returns the Identifiers of all input Variables, in
+// input order.
+func (d *litMapping) Identifiers() []Identifier {
+	ids := make([]Identifier, len(d.inorder))
+	for i, variable := range d.inorder {
+		ids[i] = variable.Identifier()
+	}
+	return ids
+}
+
+// VariableOf returns the Variable corresponding to the provided
+// literal, or a zeroVariable if no such Variable exists.
+func (d *litMapping) VariableOf(m z.Lit) Variable {
+	i, ok := d.variables[m]
+	if ok {
+		return i
+	}
+	d.errs = append(d.errs, fmt.Errorf("no variable corresponding to %s", m))
+	return zeroVariable{}
+}
+
+// ConstraintOf returns the constraint application corresponding to
+// the provided literal, or a zeroConstraint if no such constraint
+// exists.
+func (d *litMapping) ConstraintOf(m z.Lit) AppliedConstraint {
+	if a, ok := d.constraints[m]; ok {
+		return a
+	}
+	d.errs = append(d.errs, fmt.Errorf("no constraint corresponding to %s", m))
+	return AppliedConstraint{
+		Variable:   zeroVariable{},
+		Constraint: zeroConstraint{},
+	}
+}
+
+// Error returns a single error value that is an aggregation of all
+// errors encountered during a litMapping's lifetime, or nil if there have
+// been no errors. A non-nil return value likely indicates a problem
+// with the solver or constraint implementations.
+func (d *litMapping) Error() error {
+	if len(d.errs) == 0 {
+		return nil
+	}
+	s := make([]string, len(d.errs))
+	for i, err := range d.errs {
+		s[i] = err.Error()
+	}
+	return fmt.Errorf("%d errors encountered: %s", len(s), strings.Join(s, ", "))
+}
+
+// AddConstraints teaches the encoding of all constraints to g. After
+// the first call, only the parts of the encoding that have not
+// already been taught are added.
+func (d *litMapping) AddConstraints(g inter.Adder) {
+	if d.marks != nil {
+		roots := d.ConstraintLits()
+		for _, each := range d.soft {
+			roots = append(roots, each.m)
+		}
+		d.marks, _ = d.c.CnfSince(g, d.marks, roots...)
+	} else {
+		d.c.ToCnf(g)
+		d.marks = make([]int8, d.c.Len())
+		for i := range d.marks {
+			d.marks[i] = 1
+		}
+	}
+
+	// The underlying solver may only account for variables that
+	// appear in clauses, so mention the last one in a clause that
+	// always holds, so that the values of Variables that are
+	// neither constrained nor referenced can be queried.
+	if last := z.Var(d.c.Len() - 1); last > d.last {
+		g.Add(d.c.T)
+		g.Add(last.Pos())
+		g.Add(z.LitNull)
+		d.last = last
+	}
+}
+
+func (d *litMapping) AssumeConstraints(s inter.Assumable) {
+	for m := range d.constraints {
+		if _, ok := d.relaxed[m]; ok {
+			continue
+		}
+		s.Assume(m)
+	}
+	d.AssumeVariables(s)
+}
+
+// AssumeVariables assumes the required Variable literals, and that
+// absent Variables are not selected.
+func (d *litMapping) AssumeVariables(s inter.Assumable) {
+	s.Assume(d.required...)
+	for m := range d.absent {
+		s.Assume(m.Not())
+	}
+}
+
+// CardinalityConstrainer constructs a sorting network to provide
+// cardinality constraints over the provided slice of literals. Any
+// new clauses and variables, including those added to the circuit
+// since constraints were last taught to g, are translated to CNF and
+// taught to the given inter.Adder, so this function will panic if it
+// is in a test context.
+func (d *litMapping) CardinalityConstrainer(g inter.Adder, ms []z.Lit) *logic.CardSort {
+	cs := d.c.CardSort(ms)
+	for w := 0; w <= cs.N(); w++ {
+		d.Encode(g, cs.Leq(w))
+	}
+	return cs
+}
+
+// Encode teaches g the parts of the circuit rooted at the provided
+// literals that it has not already been taught. Like
+// CardinalityConstrainer, it will panic if g is in a test context.
+func (d *litMapping) Encode(g inter.Adder, ms ...z.Lit) {
+	d.marks, _ = d.c.CnfSince(g, d.marks, ms...)
+}
+
+// ViolationTerms returns the literals that are true when soft
+// constraints are violated, each weighted by the cost of violating
+// its constraint.
+func (d *litMapping) ViolationTerms() []weightedTerm {
+	var terms []weightedTerm
+	for _, s := range d.soft {
+		if s.weight > 0 {
+			terms = append(terms, weightedTerm{m: s.m.Not(), w: s.weight})
+		}
+	}
+	return terms
+}
+
+// Violations returns the soft constraint applications that do not
+// hold in the current model of g.
+func (d *litMapping) Violations(g inter.Model) []AppliedConstraint {
+	var as []AppliedConstraint
+	for _, s := range d.soft {
+		if !g.Value(s.m) {
+			as = append(as, s.applied)
+		}
+	}
+	return as
+}
+
+// AnchorIdentifiers returns a slice containing the Identifiers of
+// every Variable that is required or has at least one "anchor"
+// constraint that has not been relaxed, in the order they appear in
+// the input.
+func (d *litMapping) AnchorIdentifiers() []Identifier {
+	var ids []Identifier
+	for _, variable := range d.inorder {
+		if d.isRequired(d.LitOf(variable.Identifier())) {
+			ids = append(ids, variable.Identifier())
+			continue
+		}
+		for _, constraint := range d.ActiveConstraints(variable) {
+			if constraint.Anchor() {
+				ids = append(ids, variable.Identifier())
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// AnchorOf returns the first active anchor constraint of the Variable
+// corresponding to the provided literal. A Variable that is required
+// but has no such constraint is reported as if it were Mandatory.
+func (d *litMapping) AnchorOf(m z.Lit) AppliedConstraint {
+	variable := d.VariableOf(m)
+	for _, constraint := range d.ActiveConstraints(variable) {
+		if constraint.Anchor() {
+			return AppliedConstraint{Variable: variable, Constraint: constraint}
+		}
+	}
+	if d.isRequired(m) {
+		return AppliedConstraint{Variable: variable, Constraint: Mandatory()}
+	}
+	d.errs = append(d.errs, fmt.Errorf("variable %q is not an anchor", variable.Identifier()))
+	return AppliedConstraint{Variable: variable, Constraint: zeroConstraint{}}
+}
+
+func (d *litMapping) Variables(g inter.Model) []Variable {
+	var result []Variable
+	for _, i := range d.inorder {
+		if g.Value(d.LitOf(i.Identifier())) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+func (d *litMapping) Lits(dst []z.Lit) []z.Lit {
+	if cap(dst) < len(d.inorder) {
+		dst = make([]z.Lit, 0, len(d.inorder))
+	}
+	dst = dst[:0]
+	for _, i := range d.inorder {
+		m := d.LitOf(i.Identifier())
+		dst = append(dst, m)
+	}
+	return dst
+}
+
+func (d *litMapping) Conflicts(g inter.Assumable) []AppliedConstraint {
+	return d.ConstraintsOf(d.ConflictLits(g))
+}
+
+// ConflictLits returns the literals of the constraints among the
+// failed assumptions of the last unsatisfiable result of g.
+func (d *litMapping) ConflictLits(g inter.Assumable) []z.Lit {
+	whys := g.Why(nil)
+	ms := make([]z.Lit, 0, len(whys))
+	for _, why := range whys {
+		if _, ok := d.constraints[why]; ok {
+			ms = append(ms, why)
+		}
+	}
+	return ms
+}
+
+// ConstraintLits returns the literals of all constraints, in
+// increasing order.
+func (d *litMapping) ConstraintLits() []z.Lit {
+	ms := make([]z.Lit, 0, len(d.constraints))
+	for m := range d.constraints {
+		ms = append(ms, m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i] < ms[j] })
+	return ms
+}
+
+// ConstraintsOf returns the constraint applications corresponding to
+// the provided constraint literals.
+func (d *litMapping) ConstraintsOf(ms []z.Lit) []AppliedConstraint {
+	as := make([]AppliedConstraint, 0, len(ms))
+	for _, m := range ms {
+		as = append(as, d.ConstraintOf(m))
+	}
+	return as
+}
@@ -0,0 +1,221 @@
+package solver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-air/gini/logic"
+	"github.com/go-air/gini/z"
+)
+
+// Objective implementations describe a quantity to be minimized when
+// choosing among solutions. Objectives are optimized in order, each
+// with the optimal values of those before it held fixed, and all of
+// them take priority over search preference and over the final
+// minimization of the number of selected Variables.
+type Objective interface {
+	// String returns a human-readable description of the
+	// Objective.
+	String() string
+	// Terms returns literals of the circuit c, built using lm,
+	// whose number of true values in a solution is the value of
+	// the Objective. A literal may appear more than once in order
+	// to weight it.
+	Terms(c *logic.C, lm LitMapping) []z.Lit
+}
+
+// ObjectiveValue reports the optimal value achieved for an
+// Objective.
+type ObjectiveValue struct {
+	Objective Objective
+	Value     int
+}
+
+// joinIdentifiers returns the provided Identifiers separated by
+// commas, or empty if there are none.
+func joinIdentifiers(ids []Identifier, empty string) string {
+	if len(ids) == 0 {
+		return empty
+	}
+	s := make([]string, len(ids))
+	for i, each := range ids {
+		s[i] = string(each)
+	}
+	return strings.Join(s, ", ")
+}
+
+// known returns the subset of ids that are the Identifiers of input
+// Variables, as a set.
+func known(lm LitMapping, ids []Identifier) map[Identifier]struct{} {
+	all := make(map[Identifier]struct{})
+	for _, id := range lm.Identifiers() {
+		all[id] = struct{}{}
+	}
+	result := make(map[Identifier]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := all[id]; ok {
+			result[id] = struct{}{}
+		}
+	}
+	return result
+}
+
+// weightedObjective is implemented by Objectives whose terms carry
+// their weights, rather than repeating literals to weight them.
+type weightedObjective interface {
+	weightedTerms() []weightedTerm
+}
+
+// objectiveTerms returns the terms of the provided Objective, with
+// each literal weighted by the number of times it appears.
+func objectiveTerms(o Objective, c *logic.C, lm LitMapping) []weightedTerm {
+	if w, ok := o.(weightedObjective); ok {
+		return w.weightedTerms()
+	}
+	index := make(map[z.Lit]int)
+	var terms []weightedTerm
+	for _, m := range o.Terms(c, lm) {
+		if i, ok := index[m]; ok {
+			terms[i].w++
+			continue
+		}
+		index[m] = len(terms)
+		terms = append(terms, weightedTerm{m: m, w: 1})
+	}
+	return terms
+}
+
+type violations []weightedTerm
+
+func (objective violations) String() string {
+	return "minimize weight of violated soft constraints"
+}
+
+func (objective violations) Terms(_ *logic.C, _ LitMapping) []z.Lit {
+	var ms []z.Lit
+	for _, each := range objective {
+		for i := 0; i < each.w; i++ {
+			ms = append(ms, each.m)
+		}
+	}
+	return ms
+}
+
+func (objective violations) weightedTerms() []weightedTerm {
+	return objective
+}
+
+type changes []Identifier
+
+func (objective changes) String() string {
+	return fmt.Sprintf("minimize changes from %s", joinIdentifiers(objective, "an empty selection"))
+}
+
+func (objective changes) Terms(_ *logic.C, lm LitMapping) []z.Lit {
+	previous := known(lm, objective)
+	var ms []z.Lit
+	for _, id := range lm.Identifiers() {
+		m := lm.LitOf(id)
+		if _, ok := previous[id]; ok {
+			m = m.Not()
+		}
+		ms = append(ms, m)
+	}
+	return ms
+}
+
+// MinimizeChanges returns an Objective whose value is the number of
+// Variables that are either selected but not identified by previous,
+// or identified by previous but not selected. Identifiers that do not
+// appear in the input are ignored.
+func MinimizeChanges(previous []Identifier) Objective {
+	return changes(previous)
+}
+
+type removals []Identifier
+
+func (objective removals) String() string {
+	return fmt.Sprintf("minimize removals from %s", joinIdentifiers(objective, "an empty selection"))
+}
+
+func (objective removals) Terms(_ *logic.C, lm LitMapping) []z.Lit {
+	previous := known(lm, objective)
+	var ms []z.Lit
+	for _, id := range lm.Identifiers() {
+		if _, ok := previous[id]; ok {
+			ms = append(ms, lm.LitOf(id).Not())
+		}
+	}
+	return ms
+}
+
+// MinimizeRemovals returns an Objective whose value is the number of
+// Variables identified by previous that are not selected. Identifiers
+// that do not appear in the input are ignored.
+func MinimizeRemovals(previous []Identifier) Objective {
+	return removals(previous)
+}
+
+type minimizeSelected []Identifier
+
+func (objective minimizeSelected) String() string {
+	return fmt.Sprintf("minimize selected of %s", joinIdentifiers(objective, "no variables"))
+}
+
+func (objective minimizeSelected) Terms(_ *logic.C, lm LitMapping) []z.Lit {
+	ms := make([]z.Lit, len(objective))
+	for i, id := range objective {
+		ms[i] = lm.LitOf(id)
+	}
+	return ms
+}
+
+// MinimizeSelected returns an Objective whose value is the number of
+// selected Variables among those identified by the given
+// Identifiers.
+func MinimizeSelected(ids ...Identifier) Objective {
+	return minimizeSelected(ids)
+}
+
+type maximizeSelected []Identifier
+
+func (objective maximizeSelected) String() string {
+	return fmt.Sprintf("maximize selected of %s", joinIdentifiers(objective, "no variables"))
+}
+
+func (objective maximizeSelected) Terms(_ *logic.C, lm LitMapping) []z.Lit {
+	ms := make([]z.Lit, len(objective))
+	for i, id := range objective {
+		ms[i] = lm.LitOf(id).Not()
+	}
+	return ms
+}
+
+// MaximizeSelected returns an Objective whose value is the number of
+// unselected Variables among those identified by the given
+// Identifiers, so that minimizing it maximizes the number selected.
+func MaximizeSelected(ids ...Identifier) Objective {
+	return maximizeSelected(ids)
+}
+
+type size struct{}
+
+func (size) String() string {
+	return "minimize number of selected variables"
+}
+
+func (size) Terms(_ *logic.C, lm LitMapping) []z.Lit {
+	ids := lm.Identifiers()
+	ms := make([]z.Lit, len(ids))
+	for i, id := range ids {
+		ms[i] = lm.LitOf(id)
+	}
+	return ms
+}
+
+// MinimizeSize returns an Objective whose value is the total number
+// of selected Variables. Unlike the minimization that always follows
+// search, it takes priority over search preference.
+func MinimizeSize() Objective {
+	return size{}
+}
@@ -0,0 +1,181 @@
+package solver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-air/gini/logic"
+	"github.com/go-air/gini/z"
+	"github.com/stretchr/testify/assert"
+)
+
+// bothSelected is an Objective whose value is 1 when both of two
+// Variables are selected, exercising gates built by Terms.
+type bothSelected [2]Identifier
+
+func (objective bothSelected) String() string {
+	return "avoid selecting both"
+}
+
+func (objective bothSelected) Terms(c *logic.C, lm LitMapping) []z.Lit {
+	return []z.Lit{c.And(lm.LitOf(objective[0]), lm.LitOf(objective[1]))}
+}
+
+func TestSolveObjectives(t *testing.T) {
+	type tc struct {
+		Name       string
+		Variables  []Variable
+		Previous   []Identifier
+		Objectives []Objective
+		Installed  []Identifier
+		Values     []int
+		Implicit   []int
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "minimize selected overrides preference",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("deprecated", "x")),
+				variable("deprecated"),
+				variable("x"),
+			},
+			Objectives: []Objective{MinimizeSelected("deprecated")},
+			Installed:  []Identifier{"a", "x"},
+			Values:     []int{0},
+		},
+		{
+			Name: "maximize selected overrides preference",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "preferred")),
+				variable("preferred"),
+				variable("x", Conflict("preferred")),
+			},
+			Objectives: []Objective{MaximizeSelected("preferred")},
+			Installed:  []Identifier{"a", "preferred"},
+			Values:     []int{0},
+		},
+		{
+			Name: "earlier objectives take priority",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Objectives: []Objective{MinimizeSelected("x"), MinimizeSelected("y")},
+			Installed:  []Identifier{"a", "y"},
+			Values:     []int{0, 1},
+		},
+		{
+			Name: "later objectives take priority when reordered",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Objectives: []Objective{MinimizeSelected("y"), MinimizeSelected("x")},
+			Installed:  []Identifier{"a", "x"},
+			Values:     []int{0, 1},
+		},
+		{
+			Name: "removals are minimized before size",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+				variable("b"),
+				variable("c"),
+			},
+			Objectives: []Objective{MinimizeRemovals([]Identifier{"b", "gone"}), MinimizeSize()},
+			Installed:  []Identifier{"a", "b"},
+			Values:     []int{0, 2},
+		},
+		{
+			Name: "previous solution is optimized before objectives",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Previous:   []Identifier{"a", "x"},
+			Objectives: []Objective{MinimizeSelected("x")},
+			Installed:  []Identifier{"a", "x"},
+			Values:     []int{1},
+			Implicit:   []int{0},
+		},
+		{
+			Name: "soft constraints are optimized before objectives",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y"), Soft(2, Dependency("x"))),
+				variable("x"),
+				variable("y"),
+			},
+			Previous:   []Identifier{"a", "y"},
+			Objectives: []Objective{MinimizeSelected("x")},
+			Installed:  []Identifier{"a", "x", "y"},
+			Values:     []int{1},
+			Implicit:   []int{0, 1},
+		},
+		{
+			Name: "custom objective",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x"), Dependency("y", "z")),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+			},
+			Objectives: []Objective{bothSelected{"x", "y"}},
+			Installed:  []Identifier{"a", "x", "z"},
+			Values:     []int{0},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			options := []Option{WithInput(tt.Variables), WithObjectives(tt.Objectives...)}
+			if tt.Previous != nil {
+				options = append(options, WithPreviousSolution(tt.Previous))
+			}
+			s, err := New(options...)
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			result, err := s.SolveResult(context.TODO())
+			assert.NoError(err)
+
+			var ids []Identifier
+			for _, variable := range result.Selected {
+				ids = append(ids, variable.Identifier())
+			}
+			assert.Equal(tt.Installed, ids)
+			var values []int
+			for _, v := range result.Objectives {
+				values = append(values, v.Value)
+			}
+			assert.Equal(tt.Values, values)
+			var implicit []int
+			for _, v := range result.ImplicitObjectives {
+				implicit = append(implicit, v.Value)
+			}
+			assert.Equal(tt.Implicit, implicit)
+		})
+	}
+}
+
+func TestObjectiveString(t *testing.T) {
+	for _, tt := range []struct {
+		Objective Objective
+		Expected  string
+	}{
+		{Objective: MinimizeChanges([]Identifier{"a", "b"}), Expected: "minimize changes from a, b"},
+		{Objective: MinimizeRemovals([]Identifier{"a"}), Expected: "minimize removals from a"},
+		{Objective: MinimizeSelected("a", "b"), Expected: "minimize selected of a, b"},
+		{Objective: MaximizeSelected("a"), Expected: "maximize selected of a"},
+		{Objective: MinimizeChanges(nil), Expected: "minimize changes from an empty selection"},
+		{Objective: MinimizeRemovals(nil), Expected: "minimize removals from an empty selection"},
+		{Objective: MinimizeSelected(), Expected: "minimize selected of no variables"},
+		{Objective: MaximizeSelected(), Expected: "maximize selected of no variables"},
+		{Objective: MinimizeSize(), Expected: "minimize number of selected variables"},
+	} {
+		assert.Equal(t, tt.Expected, tt.Objective.String())
+	}
+}
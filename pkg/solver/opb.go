@@ -0,0 +1,198 @@
+package solver
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/go-air/gini/z"
+)
+
+// pbTerm is a weighted literal in a linear pseudo-boolean
+// constraint.
+type pbTerm struct {
+	w int
+	m z.Lit
+}
+
+// opb accumulates the translation of a problem into OPB format.
+type opb struct {
+	d           *litMapping
+	marks       []int8
+	body        bytes.Buffer
+	constraints int
+	objective   []pbTerm
+}
+
+// linear writes the constraint that the sum of terms is at least
+// rhs, rewriting terms of negative literals in terms of their
+// variables.
+func (o *opb) linear(terms []pbTerm, rhs int) {
+	written := 0
+	for _, t := range terms {
+		w, m := t.w, t.m
+		if w == 0 {
+			continue
+		}
+		if !m.IsPos() {
+			rhs -= w
+			w, m = -w, m.Not()
+		}
+		fmt.Fprintf(&o.body, "%+d x%d ", w, int(m.Var()))
+		written++
+	}
+	if written == 0 {
+		fmt.Fprintf(&o.body, "+0 x%d ", int(o.d.c.T.Var()))
+	}
+	fmt.Fprintf(&o.body, ">= %d ;\n", rhs)
+	o.constraints++
+}
+
+// cone writes the clauses encoding the part of the circuit rooted at
+// m that has not already been written.
+func (o *opb) cone(m z.Lit) {
+	var cnf clauses
+	o.marks, _ = o.d.c.CnfSince(&cnf, o.marks, m)
+	for _, clause := range cnf.clauses {
+		terms := make([]pbTerm, len(clause))
+		for i, each := range clause {
+			terms[i] = pbTerm{w: 1, m: each}
+		}
+		o.linear(terms, 1)
+	}
+}
+
+// constraint writes the translation of a single applied constraint,
+// whose literal is m.
+func (o *opb) constraint(constraint Constraint, subject Identifier, m z.Lit) {
+	fmt.Fprintf(&o.body, "* %s\n", constraint.String(subject))
+	s := o.d.c.T
+	if subject != "" {
+		s = o.d.LitOf(subject)
+	}
+	info := Inspect(constraint)
+	terms := func(w int) []pbTerm {
+		ts := make([]pbTerm, len(info.Identifiers))
+		for i, id := range info.Identifiers {
+			ts[i] = pbTerm{w: w, m: o.d.LitOf(id)}
+		}
+		return ts
+	}
+
+	switch info.Kind {
+	case KindMandatory:
+		o.linear([]pbTerm{{w: 1, m: s}}, 1)
+	case KindProhibited:
+		o.linear([]pbTerm{{w: 1, m: s.Not()}}, 1)
+	case KindDependency:
+		o.linear(append([]pbTerm{{w: 1, m: s.Not()}}, terms(1)...), 1)
+	case KindConflict:
+		o.linear([]pbTerm{{w: 1, m: s.Not()}, {w: 1, m: o.d.LitOf(info.Identifiers[0]).Not()}}, 1)
+	case KindAtMost:
+		o.linear(terms(-1), -info.N)
+	case KindAtLeast:
+		o.linear(append([]pbTerm{{w: info.N, m: s.Not()}}, terms(1)...), info.N)
+	case KindExactly:
+		o.linear(append([]pbTerm{{w: info.N, m: s.Not()}}, terms(1)...), info.N)
+		slack := len(info.Identifiers) - info.N
+		if slack < 0 {
+			slack = 0
+		}
+		o.linear(append([]pbTerm{{w: -slack, m: s}}, terms(-1)...), -info.N-slack)
+	case KindWeightedAtMost:
+		ts := terms(0)
+		for i := range ts {
+			ts[i].w = -info.Coefficients[i]
+		}
+		o.linear(ts, -info.N)
+	case KindSelected:
+		o.linear(terms(1), 1)
+	default:
+		o.cone(m)
+		o.linear([]pbTerm{{w: 1, m: m}}, 1)
+	}
+}
+
+// WriteOPB writes the problem described by the given Options to w as
+// a linear pseudo-boolean problem in OPB format, suitable for
+// cross-checking results with external pseudo-boolean solvers.
+//
+// Each input Variable is represented by the OPB variable whose name
+// is given by a comment line of the form
+// "* variable <name> <identifier>", and each applied constraint by
+// one or more constraints preceded by a comment line containing its
+// description. Built-in Constraints
+// are translated directly, while combinators, soft Constraints and
+// Constraints not provided by this package are translated by way of
+// the clauses of their encoding, which introduce auxiliary
+// variables. When the problem contains soft Constraints, the
+// objective minimizes the total weight of those violated, less a
+// constant offset given in a comment; other Objectives are not
+// represented.
+func WriteOPB(w io.Writer, options ...Option) error {
+	s, err := New(options...)
+	if err != nil {
+		return err
+	}
+	o := opb{d: s.(*solver).litMap}
+	d := o.d
+
+	// The first variable of the circuit is constant.
+	o.linear([]pbTerm{{w: 1, m: d.c.T}}, 1)
+
+	for _, id := range d.AnchorIdentifiers() {
+		fmt.Fprintf(&o.body, "* %s is an anchor\n", id)
+		o.linear([]pbTerm{{w: 1, m: d.LitOf(id)}}, 1)
+	}
+	// Each soft application contributes its own objective term,
+	// even if its literal is shared with other applications.
+	write := func(ms []z.Lit, constraints []Constraint, subject Identifier) {
+		for i, m := range ms {
+			if m == z.LitNull {
+				continue
+			}
+			if s, ok := constraints[i].(soft); ok {
+				fmt.Fprintf(&o.body, "* %s\n", constraints[i].String(subject))
+				o.cone(m)
+				if s.weight > 0 {
+					o.objective = append(o.objective, pbTerm{w: s.weight, m: m.Not()})
+				}
+				continue
+			}
+			o.constraint(constraints[i], subject, m)
+		}
+	}
+	for _, variable := range d.inorder {
+		write(d.applied[variable.Identifier()], variable.Constraints(), variable.Identifier())
+	}
+	write(d.globalLits, d.globals, "")
+
+	if err := d.Error(); err != nil {
+		return err
+	}
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "* #variable= %d #constraint= %d\n", d.c.Len()-1, o.constraints)
+	for _, variable := range d.inorder {
+		fmt.Fprintf(b, "* variable x%d %s\n", int(d.LitOf(variable.Identifier()).Var()), variable.Identifier())
+	}
+	if len(o.objective) > 0 {
+		offset := 0
+		fmt.Fprint(b, "min:")
+		for _, t := range o.objective {
+			w, m := t.w, t.m
+			if !m.IsPos() {
+				offset += w
+				w, m = -w, m.Not()
+			}
+			fmt.Fprintf(b, " %+d x%d", w, int(m.Var()))
+		}
+		fmt.Fprint(b, " ;\n")
+		fmt.Fprintf(b, "* objective offset %d\n", offset)
+	}
+	if _, err := o.body.WriteTo(b); err != nil {
+		return err
+	}
+	return b.Flush()
+}
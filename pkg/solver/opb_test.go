@@ -0,0 +1,198 @@
+package solver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pbInstance is a parsed OPB problem, for checking by exhaustive
+// enumeration.
+type pbInstance struct {
+	offset      int
+	objective   map[int]int
+	constraints []pbInequality
+}
+
+type pbInequality struct {
+	terms map[int]int
+	rhs   int
+}
+
+func parseOPB(t *testing.T, data []byte) pbInstance {
+	var p pbInstance
+	terms := func(fields []string) map[int]int {
+		ts := make(map[int]int)
+		for i := 0; i+1 < len(fields); i += 2 {
+			w, err := strconv.Atoi(fields[i])
+			require.NoError(t, err)
+			x, err := strconv.Atoi(strings.TrimPrefix(fields[i+1], "x"))
+			require.NoError(t, err)
+			ts[x] += w
+		}
+		return ts
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case fields[0] == "*" && len(fields) == 4 && fields[1] == "objective" && fields[2] == "offset":
+			n, err := strconv.Atoi(fields[3])
+			require.NoError(t, err)
+			p.offset = n
+		case fields[0] == "*":
+		case fields[0] == "min:":
+			p.objective = terms(fields[1 : len(fields)-1])
+		default:
+			rhs, err := strconv.Atoi(fields[len(fields)-2])
+			require.NoError(t, err)
+			p.constraints = append(p.constraints, pbInequality{
+				terms: terms(fields[:len(fields)-3]),
+				rhs:   rhs,
+			})
+		}
+	}
+	return p
+}
+
+// optimum returns the least objective value, plus offset, among the
+// assignments satisfying every constraint, and false if there are
+// none.
+func (p pbInstance) optimum() (int, bool) {
+	index := make(map[int]int)
+	for _, c := range p.constraints {
+		for x := range c.terms {
+			if _, ok := index[x]; !ok {
+				index[x] = len(index)
+			}
+		}
+	}
+	for x := range p.objective {
+		if _, ok := index[x]; !ok {
+			index[x] = len(index)
+		}
+	}
+
+	best, found := 0, false
+	for bits := 0; bits < 1<<len(index); bits++ {
+		value := func(x int) int {
+			return (bits >> index[x]) & 1
+		}
+		feasible := true
+		for _, c := range p.constraints {
+			sum := 0
+			for x, w := range c.terms {
+				sum += w * value(x)
+			}
+			if sum < c.rhs {
+				feasible = false
+				break
+			}
+		}
+		if !feasible {
+			continue
+		}
+		cost := p.offset
+		for x, w := range p.objective {
+			cost += w * value(x)
+		}
+		if !found || cost < best {
+			best, found = cost, true
+		}
+	}
+	return best, found
+}
+
+func TestWriteOPB(t *testing.T) {
+	type tc struct {
+		Name        string
+		Variables   []Variable
+		Constraints []Constraint
+		Feasible    bool
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "built-in constraints",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("b", "c"), Conflict("d")),
+				variable("b", Prohibited()),
+				variable("c", AtLeast(1, "d", "e"), Exactly(1, "e", "b")),
+				variable("d"),
+				variable("e"),
+			},
+			Constraints: []Constraint{AtMost(2, "a", "c", "d")},
+			Feasible:    true,
+		},
+		{
+			Name: "weighted sum prevents resolution",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x", WeightedAtMost(-1, Term{"x", 3}, Term{"y", -2}, Term{"a", 1})),
+				variable("y", Conflict("a")),
+			},
+		},
+		{
+			Name: "combinators",
+			Variables: []Variable{
+				variable("a", Mandatory(), Implies(Selected("b"), Not(Selected("c"))), Or(Selected("b"), Selected("c"))),
+				variable("b", Selected("a")),
+				variable("c"),
+			},
+			Feasible: true,
+		},
+		{
+			Name: "soft constraints",
+			Variables: []Variable{
+				variable("a", Mandatory(), Soft(2, Dependency("b")), Soft(1, Dependency("c"))),
+				variable("b", Conflict("c")),
+				variable("c"),
+			},
+			Constraints: []Constraint{Soft(3, Selected("c"))},
+			Feasible:    true,
+		},
+		{
+			Name: "duplicate soft constraints",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x"), Dependency("y")),
+				variable("x"),
+				variable("y"),
+			},
+			Constraints: []Constraint{Soft(1, AtMost(1, "x", "y")), Soft(2, AtMost(1, "x", "y"))},
+			Feasible:    true,
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+			options := []Option{WithInput(tt.Variables), WithGlobalConstraints(tt.Constraints...)}
+
+			var b bytes.Buffer
+			require.NoError(t, WriteOPB(&b, options...))
+			p := parseOPB(t, b.Bytes())
+			optimum, feasible := p.optimum()
+			assert.Equal(tt.Feasible, feasible)
+
+			s, err := New(options...)
+			require.NoError(t, err)
+			result, err := s.SolveResult(context.TODO())
+			var ns NotSatisfiable
+			if !feasible {
+				assert.True(errors.As(err, &ns), "expected NotSatisfiable, got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			violated := 0
+			for _, a := range result.Violated {
+				violated += Inspect(a.Constraint).Weight
+			}
+			assert.Equal(optimum, violated)
+		})
+	}
+}
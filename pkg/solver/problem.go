@@ -0,0 +1,377 @@
+package solver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ProblemVersion is the version of the serialization format produced
+// by NewProblem. Documents of any other version are rejected when
+// unmarshaled.
+const ProblemVersion = "v1"
+
+// Problem is a serializable description of a problem and the Options
+// used to solve it. Its JSON and YAML representations form a
+// versioned file format, in which Variables and their Constraints
+// appear in input order:
+//
+//	version: v1
+//	variables:
+//	- id: a
+//	  constraints:
+//	  - kind: mandatory
+//	  - kind: dependency
+//	    identifiers: [b, c]
+//	- id: b
+//	- id: c
+//	options:
+//	  globalConstraints:
+//	  - kind: atMost
+//	    identifiers: [b, c]
+//	    count: 1
+//	  previousSolution: null
+//	  objectives:
+//	  - kind: maximizeSelected
+//	    identifiers: [c]
+//
+// Only the built-in Constraints and Objectives provided by this
+// package can be represented.
+type Problem struct {
+	Version   string            `json:"version"`
+	Variables []ProblemVariable `json:"variables"`
+	Options   ProblemOptions    `json:"options"`
+}
+
+// ProblemVariable is the serializable form of a Variable.
+type ProblemVariable struct {
+	ID          Identifier          `json:"id"`
+	Constraints []ProblemConstraint `json:"constraints,omitempty"`
+}
+
+// ProblemConstraint is the serializable form of a Constraint. Its
+// fields correspond to those of the ConstraintInfo returned by
+// Inspect, with N encoded as "count".
+type ProblemConstraint struct {
+	Kind         ConstraintKind      `json:"kind"`
+	Identifiers  []Identifier        `json:"identifiers,omitempty"`
+	Coefficients []int               `json:"coefficients,omitempty"`
+	N            int                 `json:"count,omitempty"`
+	Weight       int                 `json:"weight,omitempty"`
+	Operands     []ProblemConstraint `json:"operands,omitempty"`
+}
+
+// ProblemOptions is the serializable form of the Options, other than
+// WithInput, used to construct a Solver. WithTracer is not
+// represented, since it does not affect the solution.
+type ProblemOptions struct {
+	GlobalConstraints []ProblemConstraint `json:"globalConstraints,omitempty"`
+	// PreviousSolution is null unless WithPreviousSolution was
+	// used, since an empty previous solution is distinct from none
+	// at all.
+	PreviousSolution []Identifier       `json:"previousSolution"`
+	Objectives       []ProblemObjective `json:"objectives,omitempty"`
+	MinimalConflicts bool               `json:"minimalConflicts,omitempty"`
+}
+
+// ObjectiveKind identifies the type of an Objective.
+type ObjectiveKind string
+
+const (
+	KindMinimizeChanges  ObjectiveKind = "minimizeChanges"
+	KindMinimizeRemovals ObjectiveKind = "minimizeRemovals"
+	KindMinimizeSelected ObjectiveKind = "minimizeSelected"
+	KindMaximizeSelected ObjectiveKind = "maximizeSelected"
+	KindMinimizeSize     ObjectiveKind = "minimizeSize"
+)
+
+// ProblemObjective is the serializable form of an Objective.
+type ProblemObjective struct {
+	Kind        ObjectiveKind `json:"kind"`
+	Identifiers []Identifier  `json:"identifiers,omitempty"`
+}
+
+// NewProblem returns a Problem describing the Solver that would be
+// constructed by passing the given Options to New. It returns an
+// error if any of the Options fails, or if the problem contains a
+// Constraint or Objective that is not provided by this package.
+func NewProblem(options ...Option) (Problem, error) {
+	var s solver
+	for _, option := range options {
+		if err := option(&s); err != nil {
+			return Problem{}, err
+		}
+	}
+
+	p := Problem{
+		Version:   ProblemVersion,
+		Variables: []ProblemVariable{},
+		Options: ProblemOptions{
+			PreviousSolution: s.previous,
+			MinimalConflicts: s.minimalConflicts,
+		},
+	}
+	if s.litMap != nil {
+		for _, variable := range s.litMap.inorder {
+			constraints, err := newProblemConstraints(variable.Constraints())
+			if err != nil {
+				return Problem{}, fmt.Errorf("variable %q: %w", variable.Identifier(), err)
+			}
+			p.Variables = append(p.Variables, ProblemVariable{
+				ID:          variable.Identifier(),
+				Constraints: constraints,
+			})
+		}
+	}
+
+	var err error
+	if p.Options.GlobalConstraints, err = newProblemConstraints(s.globals); err != nil {
+		return Problem{}, fmt.Errorf("global constraints: %w", err)
+	}
+	for _, objective := range s.objectives {
+		o, err := newProblemObjective(objective)
+		if err != nil {
+			return Problem{}, err
+		}
+		p.Options.Objectives = append(p.Options.Objectives, o)
+	}
+	return p, nil
+}
+
+func newProblemConstraints(constraints []Constraint) ([]ProblemConstraint, error) {
+	var result []ProblemConstraint
+	for _, constraint := range constraints {
+		info := Inspect(constraint)
+		if info.Kind == KindCustom {
+			return nil, fmt.Errorf("constraint %q cannot be serialized", constraint.String(""))
+		}
+		operands, err := newProblemConstraints(info.Operands)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ProblemConstraint{
+			Kind:         info.Kind,
+			Identifiers:  info.Identifiers,
+			Coefficients: info.Coefficients,
+			N:            info.N,
+			Weight:       info.Weight,
+			Operands:     operands,
+		})
+	}
+	return result, nil
+}
+
+func newProblemObjective(objective Objective) (ProblemObjective, error) {
+	switch o := objective.(type) {
+	case changes:
+		return ProblemObjective{Kind: KindMinimizeChanges, Identifiers: o}, nil
+	case removals:
+		return ProblemObjective{Kind: KindMinimizeRemovals, Identifiers: o}, nil
+	case minimizeSelected:
+		return ProblemObjective{Kind: KindMinimizeSelected, Identifiers: o}, nil
+	case maximizeSelected:
+		return ProblemObjective{Kind: KindMaximizeSelected, Identifiers: o}, nil
+	case size:
+		return ProblemObjective{Kind: KindMinimizeSize}, nil
+	}
+	return ProblemObjective{}, fmt.Errorf("objective %q cannot be serialized", objective.String())
+}
+
+// problemVariable is the Variable implementation produced from a
+// ProblemVariable.
+type problemVariable struct {
+	id          Identifier
+	constraints []Constraint
+}
+
+func (v problemVariable) Identifier() Identifier {
+	return v.id
+}
+
+func (v problemVariable) Constraints() []Constraint {
+	return v.constraints
+}
+
+// Input returns the Variables described by the receiver, in order.
+func (p Problem) Input() ([]Variable, error) {
+	variables := make([]Variable, len(p.Variables))
+	for i, v := range p.Variables {
+		constraints, err := problemConstraints(v.Constraints)
+		if err != nil {
+			return nil, fmt.Errorf("variable %q: %w", v.ID, err)
+		}
+		variables[i] = problemVariable{id: v.ID, constraints: constraints}
+	}
+	return variables, nil
+}
+
+// SolverOptions returns Options that, when passed to New, construct
+// a Solver for the problem described by the receiver.
+func (p Problem) SolverOptions() ([]Option, error) {
+	variables, err := p.Input()
+	if err != nil {
+		return nil, err
+	}
+	options := []Option{WithInput(variables)}
+
+	globals, err := problemConstraints(p.Options.GlobalConstraints)
+	if err != nil {
+		return nil, fmt.Errorf("global constraints: %w", err)
+	}
+	if len(globals) > 0 {
+		options = append(options, WithGlobalConstraints(globals...))
+	}
+	if p.Options.PreviousSolution != nil {
+		options = append(options, WithPreviousSolution(p.Options.PreviousSolution))
+	}
+	var objectives []Objective
+	for _, o := range p.Options.Objectives {
+		objective, err := problemObjective(o)
+		if err != nil {
+			return nil, err
+		}
+		objectives = append(objectives, objective)
+	}
+	if len(objectives) > 0 {
+		options = append(options, WithObjectives(objectives...))
+	}
+	if p.Options.MinimalConflicts {
+		options = append(options, WithMinimalConflicts())
+	}
+	return options, nil
+}
+
+func problemConstraints(pcs []ProblemConstraint) ([]Constraint, error) {
+	var result []Constraint
+	for _, pc := range pcs {
+		operands, err := problemConstraints(pc.Operands)
+		if err != nil {
+			return nil, err
+		}
+		arity := func(n int) error {
+			if len(operands) != n {
+				return fmt.Errorf("%s constraint requires %d operands, got %d", pc.Kind, n, len(operands))
+			}
+			return nil
+		}
+		var constraint Constraint
+		switch pc.Kind {
+		case KindMandatory:
+			constraint = Mandatory()
+		case KindProhibited:
+			constraint = Prohibited()
+		case KindDependency:
+			constraint = Dependency(pc.Identifiers...)
+		case KindConflict:
+			if len(pc.Identifiers) != 1 {
+				return nil, fmt.Errorf("%s constraint requires 1 identifier, got %d", pc.Kind, len(pc.Identifiers))
+			}
+			constraint = Conflict(pc.Identifiers[0])
+		case KindAtMost:
+			constraint = AtMost(pc.N, pc.Identifiers...)
+		case KindAtLeast:
+			constraint = AtLeast(pc.N, pc.Identifiers...)
+		case KindExactly:
+			constraint = Exactly(pc.N, pc.Identifiers...)
+		case KindWeightedAtMost:
+			if len(pc.Coefficients) != len(pc.Identifiers) {
+				return nil, fmt.Errorf("%s constraint requires %d coefficients, got %d", pc.Kind, len(pc.Identifiers), len(pc.Coefficients))
+			}
+			terms := make([]Term, len(pc.Identifiers))
+			for i, id := range pc.Identifiers {
+				terms[i] = Term{Identifier: id, Coefficient: pc.Coefficients[i]}
+			}
+			constraint = WeightedAtMost(pc.N, terms...)
+		case KindSelected:
+			if len(pc.Identifiers) != 1 {
+				return nil, fmt.Errorf("%s constraint requires 1 identifier, got %d", pc.Kind, len(pc.Identifiers))
+			}
+			constraint = Selected(pc.Identifiers[0])
+		case KindAnd:
+			constraint = And(operands...)
+		case KindOr:
+			constraint = Or(operands...)
+		case KindNot:
+			if err := arity(1); err != nil {
+				return nil, err
+			}
+			constraint = Not(operands[0])
+		case KindImplies:
+			if err := arity(2); err != nil {
+				return nil, err
+			}
+			constraint = Implies(operands[0], operands[1])
+		case KindSoft:
+			if err := arity(1); err != nil {
+				return nil, err
+			}
+			constraint = Soft(pc.Weight, operands[0])
+		default:
+			return nil, fmt.Errorf("unknown constraint kind %q", pc.Kind)
+		}
+		result = append(result, constraint)
+	}
+	return result, nil
+}
+
+func problemObjective(o ProblemObjective) (Objective, error) {
+	switch o.Kind {
+	case KindMinimizeChanges:
+		return MinimizeChanges(o.Identifiers), nil
+	case KindMinimizeRemovals:
+		return MinimizeRemovals(o.Identifiers), nil
+	case KindMinimizeSelected:
+		return MinimizeSelected(o.Identifiers...), nil
+	case KindMaximizeSelected:
+		return MaximizeSelected(o.Identifiers...), nil
+	case KindMinimizeSize:
+		return MinimizeSize(), nil
+	}
+	return nil, fmt.Errorf("unknown objective kind %q", o.Kind)
+}
+
+// MarshalProblemJSON returns the JSON encoding of p.
+func MarshalProblemJSON(p Problem) ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// UnmarshalProblemJSON parses a JSON-encoded Problem. It returns an
+// error if the document is not of version ProblemVersion or contains
+// unknown fields.
+func UnmarshalProblemJSON(data []byte) (Problem, error) {
+	var header struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return Problem{}, err
+	}
+	if header.Version != ProblemVersion {
+		return Problem{}, fmt.Errorf("unsupported problem version %q", header.Version)
+	}
+
+	var p Problem
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return Problem{}, err
+	}
+	return p, nil
+}
+
+// MarshalProblemYAML returns the YAML encoding of p.
+func MarshalProblemYAML(p Problem) ([]byte, error) {
+	return yaml.Marshal(p)
+}
+
+// UnmarshalProblemYAML parses a YAML-encoded Problem. It returns an
+// error if the document is not of version ProblemVersion or contains
+// unknown fields.
+func UnmarshalProblemYAML(data []byte) (Problem, error) {
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return Problem{}, err
+	}
+	return UnmarshalProblemJSON(data)
+}
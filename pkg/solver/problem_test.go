@@ -0,0 +1,165 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemRoundTrip(t *testing.T) {
+	options := []Option{
+		WithInput([]Variable{
+			variable("a", Mandatory(), Dependency("b", "c"), Soft(2, Conflict("d"))),
+			variable("b", AtLeast(1, "d"), Implies(Selected("c"), Not(Or(Prohibited())))),
+			variable("c", Exactly(0, "d"), And()),
+			variable("d", AtMost(2, "a", "b"), WeightedAtMost(3, Term{"a", 2}, Term{"c", -1})),
+		}),
+		WithGlobalConstraints(AtMost(1, "b", "c")),
+		WithPreviousSolution([]Identifier{}),
+		WithObjectives(
+			MinimizeChanges([]Identifier{"a"}),
+			MinimizeRemovals([]Identifier{"b"}),
+			MinimizeSelected("c"),
+			MaximizeSelected("d"),
+			MinimizeSize(),
+		),
+		WithMinimalConflicts(),
+	}
+
+	p, err := NewProblem(options...)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		Name      string
+		Marshal   func(Problem) ([]byte, error)
+		Unmarshal func([]byte) (Problem, error)
+	}{
+		{Name: "json", Marshal: MarshalProblemJSON, Unmarshal: UnmarshalProblemJSON},
+		{Name: "yaml", Marshal: MarshalProblemYAML, Unmarshal: UnmarshalProblemYAML},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			data, err := tt.Marshal(p)
+			require.NoError(t, err)
+			decoded, err := tt.Unmarshal(data)
+			require.NoError(t, err)
+
+			replayed, err := decoded.SolverOptions()
+			require.NoError(t, err)
+			again, err := NewProblem(replayed...)
+			require.NoError(t, err)
+			assert.Equal(p, again)
+
+			redata, err := tt.Marshal(again)
+			require.NoError(t, err)
+			assert.Equal(string(data), string(redata))
+
+			s, err := New(options...)
+			require.NoError(t, err)
+			expected, expectedErr := s.SolveResult(context.TODO())
+			assert.NoError(expectedErr)
+			s, err = New(replayed...)
+			require.NoError(t, err)
+			actual, actualErr := s.SolveResult(context.TODO())
+			assert.Equal(fmt.Sprint(expectedErr), fmt.Sprint(actualErr))
+			assert.Equal(identifiersOf(expected.Selected), identifiersOf(actual.Selected))
+		})
+	}
+}
+
+func TestUnmarshalProblem(t *testing.T) {
+	type tc struct {
+		Name     string
+		YAML     string
+		Selected []Identifier
+		Error    string
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "example",
+			YAML: `
+version: v1
+variables:
+- id: a
+  constraints:
+  - kind: mandatory
+  - kind: dependency
+    identifiers: [b, c]
+- id: b
+- id: c
+options:
+  globalConstraints:
+  - kind: atMost
+    identifiers: [b, c]
+    count: 1
+  previousSolution: null
+  objectives:
+  - kind: maximizeSelected
+    identifiers: [c]
+`,
+			Selected: []Identifier{"a", "c"},
+		},
+		{
+			Name:  "unsupported version",
+			YAML:  "version: v2\nvariables: []\n",
+			Error: `unsupported problem version "v2"`,
+		},
+		{
+			Name:  "unknown field",
+			YAML:  "version: v1\nvariables: []\nsolver: gini\n",
+			Error: `json: unknown field "solver"`,
+		},
+		{
+			Name:  "unknown constraint",
+			YAML:  "version: v1\nvariables:\n- id: a\n  constraints:\n  - kind: requiresAll\n",
+			Error: `variable "a": unknown constraint kind "requiresAll"`,
+		},
+		{
+			Name:  "missing operand",
+			YAML:  "version: v1\nvariables:\n- id: a\n  constraints:\n  - kind: not\n",
+			Error: `variable "a": not constraint requires 1 operands, got 0`,
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p, err := UnmarshalProblemYAML([]byte(tt.YAML))
+			var options []Option
+			if err == nil {
+				options, err = p.SolverOptions()
+			}
+			if tt.Error != "" {
+				assert.EqualError(err, tt.Error)
+				return
+			}
+			require.NoError(t, err)
+
+			s, err := New(options...)
+			require.NoError(t, err)
+			selected, err := s.Solve(context.TODO())
+			assert.NoError(err)
+			assert.Equal(tt.Selected, identifiersOf(selected))
+		})
+	}
+}
+
+func TestNewProblemUnsupported(t *testing.T) {
+	_, err := NewProblem(WithInput([]Variable{variable("a", requiresAll{"b"})}))
+	assert.EqualError(t, err, `variable "a": constraint " requires all of [b]" cannot be serialized`)
+
+	_, err = NewProblem(WithObjectives(bothSelected{"a", "b"}))
+	assert.Error(t, err)
+}
+
+func identifiersOf(variables []Variable) []Identifier {
+	var ids []Identifier
+	for _, variable := range variables {
+		ids = append(ids, variable.Identifier())
+	}
+	return ids
+}
@@ -0,0 +1,362 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// holds reports whether the provided Constraint, as applied to
+// subject, is satisfied when exactly the Variables for which selected
+// returns true are selected. It follows the documented semantics of
+// each built-in Constraint rather than their encoding.
+func holds(constraint Constraint, subject Identifier, selected func(Identifier) bool) bool {
+	self := subject == "" || selected(subject)
+	info := Inspect(constraint)
+	count := 0
+	for _, id := range info.Identifiers {
+		if selected(id) {
+			count++
+		}
+	}
+	switch info.Kind {
+	case KindMandatory:
+		return self
+	case KindProhibited:
+		return !self
+	case KindDependency:
+		return !self || count > 0
+	case KindConflict:
+		return !self || count == 0
+	case KindAtMost:
+		return count <= info.N
+	case KindAtLeast:
+		return !self || count >= info.N
+	case KindExactly:
+		return !self || count == info.N
+	case KindWeightedAtMost:
+		sum := 0
+		for i, id := range info.Identifiers {
+			if selected(id) {
+				sum += info.Coefficients[i]
+			}
+		}
+		return sum <= info.N
+	case KindSelected:
+		return count > 0
+	case KindAnd:
+		for _, each := range info.Operands {
+			if !holds(each, subject, selected) {
+				return false
+			}
+		}
+		return true
+	case KindOr:
+		for _, each := range info.Operands {
+			if holds(each, subject, selected) {
+				return true
+			}
+		}
+		return false
+	case KindNot:
+		return !holds(info.Operands[0], subject, selected)
+	case KindImplies:
+		return !holds(info.Operands[0], subject, selected) || holds(info.Operands[1], subject, selected)
+	}
+	panic("reference solver does not support " + constraint.String(subject))
+}
+
+// reference is an exhaustive implementation of the semantics of
+// Solve for small problems without soft Constraints or Objectives.
+type reference struct {
+	variables []Variable
+	index     map[Identifier]int
+	globals   []Constraint
+	feasible  []uint64 // every selection that satisfies all constraints
+}
+
+func newReference(variables []Variable, globals []Constraint) *reference {
+	r := reference{
+		variables: variables,
+		index:     make(map[Identifier]int, len(variables)),
+		globals:   globals,
+	}
+	for i, variable := range variables {
+		r.index[variable.Identifier()] = i
+	}
+	for bits := uint64(0); bits < 1<<len(variables); bits++ {
+		selected := func(id Identifier) bool {
+			i, ok := r.index[id]
+			return ok && bits&(1<<i) != 0
+		}
+		ok := true
+		for _, variable := range variables {
+			for _, constraint := range variable.Constraints() {
+				ok = ok && holds(constraint, variable.Identifier(), selected)
+			}
+		}
+		for _, constraint := range globals {
+			ok = ok && holds(constraint, "", selected)
+		}
+		if ok {
+			r.feasible = append(r.feasible, bits)
+		}
+	}
+	return &r
+}
+
+// extensible reports whether some feasible selection contains every
+// Variable in bits.
+func (r *reference) extensible(bits uint64) bool {
+	for _, f := range r.feasible {
+		if f&bits == bits {
+			return true
+		}
+	}
+	return false
+}
+
+// choices returns the choices introduced by the provided Constraints,
+// each a list of Variable indices in order of preference.
+func (r *reference) choices(constraints []Constraint) [][]int {
+	var result [][]int
+	for _, constraint := range constraints {
+		var c []int
+		for _, id := range constraint.Order() {
+			// Candidates that are not part of the problem
+			// can never be selected.
+			if i, ok := r.index[id]; ok {
+				c = append(c, i)
+			}
+		}
+		if len(c) > 0 {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// search makes each choice in the queue in turn, depth first, and
+// returns the guessed Variables at the first point at which no
+// choices remain and the guesses can be extended to a solution. A
+// choice is skipped if one of its candidates has already been
+// guessed, and is given up once none of its candidates lead to a
+// solution. Guessing a Variable appends the choices introduced by
+// its Constraints to the queue.
+func (r *reference) search(base, guessed uint64, queue [][]int) (uint64, bool) {
+	if !r.extensible(base | guessed) {
+		return 0, false
+	}
+	if len(queue) == 0 {
+		return guessed, true
+	}
+	c, rest := queue[0], queue[1:]
+	for _, i := range c {
+		if guessed&(1<<i) != 0 {
+			return r.search(base, guessed, rest)
+		}
+	}
+	for _, i := range c {
+		next := append(append([][]int{}, rest...), r.choices(r.variables[i].Constraints())...)
+		if result, ok := r.search(base, guessed|1<<i, next); ok {
+			return result, true
+		}
+	}
+	return r.search(base, guessed, rest)
+}
+
+// Solve returns every selection that Solve may return for the
+// problem, or nil if it has no solution. After search, Solve selects
+// a solution containing the guessed Variables from which no other
+// Variable can be removed; which one depends on the assignment found
+// by the underlying SAT solver.
+func (r *reference) Solve() [][]Identifier {
+	var anchors uint64
+	var queue [][]int
+	for i, variable := range r.variables {
+		for _, constraint := range variable.Constraints() {
+			if constraint.Anchor() {
+				anchors |= 1 << i
+				queue = append(queue, []int{i})
+				break
+			}
+		}
+	}
+	queue = append(queue, r.choices(r.globals)...)
+
+	guessed, ok := r.search(anchors, 0, queue)
+	if !ok {
+		return nil
+	}
+
+	var result [][]Identifier
+	for _, f := range r.feasible {
+		if f&guessed != guessed {
+			continue
+		}
+		minimal := true
+		for _, g := range r.feasible {
+			if g != f && g&guessed == guessed && g&f == g {
+				minimal = false
+				break
+			}
+		}
+		if !minimal {
+			continue
+		}
+		ids := []Identifier{}
+		for i, variable := range r.variables {
+			if f&(1<<i) != 0 {
+				ids = append(ids, variable.Identifier())
+			}
+		}
+		result = append(result, ids)
+	}
+	return result
+}
+
+// randomInput returns a small random problem of the same shape as
+// BenchmarkInput, together with random global Constraints.
+func randomInput(rng *rand.Rand, length int) ([]Variable, []Constraint) {
+	const (
+		pMandatory  = .2
+		pDependency = .4
+		nDependency = 4
+		pConflict   = .15
+		pAtMost     = .1
+		pGlobal     = .3
+	)
+
+	id := func(i int) Identifier {
+		return Identifier(strconv.Itoa(i))
+	}
+	others := func(i, n int) []Identifier {
+		var ids []Identifier
+		for x := 0; x < n; x++ {
+			y := i
+			for y == i {
+				y = rng.Intn(length)
+			}
+			ids = append(ids, id(y))
+		}
+		return ids
+	}
+
+	variables := make([]Variable, length)
+	for i := range variables {
+		var c []Constraint
+		if rng.Float64() < pMandatory {
+			c = append(c, Mandatory())
+		}
+		if rng.Float64() < pDependency {
+			c = append(c, Dependency(others(i, rng.Intn(nDependency-1)+1)...))
+		}
+		if rng.Float64() < pConflict {
+			c = append(c, Conflict(others(i, 1)[0]))
+		}
+		if rng.Float64() < pAtMost {
+			ids := others(i, rng.Intn(nDependency-1)+2)
+			c = append(c, AtMost(rng.Intn(len(ids)), ids...))
+		}
+		variables[i] = TestVariable{
+			identifier:  id(i),
+			constraints: c,
+		}
+	}
+
+	var globals []Constraint
+	if rng.Float64() < pGlobal {
+		globals = append(globals, Dependency(others(-1, rng.Intn(nDependency-1)+1)...))
+	}
+	if rng.Float64() < pGlobal {
+		ids := others(-1, rng.Intn(nDependency-1)+2)
+		globals = append(globals, AtMost(rng.Intn(len(ids)), ids...))
+	}
+	return variables, globals
+}
+
+func TestReference(t *testing.T) {
+	type tc struct {
+		Name      string
+		Variables []Variable
+		Expected  [][]Identifier
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "preferred dependency",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Expected: [][]Identifier{{"a", "x"}},
+		},
+		{
+			Name: "alternative dependency",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x", Conflict("a")),
+				variable("y"),
+			},
+			Expected: [][]Identifier{{"a", "y"}},
+		},
+		{
+			Name: "minimal but not smallest",
+			Variables: []Variable{
+				variable("a", Mandatory(), AtLeast(2, "x", "y", "z")),
+				variable("x"),
+				variable("y", Dependency("w")),
+				variable("z"),
+				variable("w"),
+			},
+			Expected: [][]Identifier{{"a", "x", "z"}, {"a", "x", "y", "w"}},
+		},
+		{
+			Name: "not satisfiable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Prohibited()),
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, newReference(tt.Variables, nil).Solve())
+		})
+	}
+}
+
+func TestSolveDifferential(t *testing.T) {
+	const (
+		iterations = 500
+		length     = 10
+	)
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < iterations; i++ {
+		variables, globals := randomInput(rng, length)
+		expected := newReference(variables, globals).Solve()
+
+		s, err := New(WithInput(variables), WithGlobalConstraints(globals...))
+		require.NoError(t, err)
+		installed, err := s.Solve(context.Background())
+		if expected == nil {
+			var ns NotSatisfiable
+			if !errors.As(err, &ns) {
+				t.Fatalf("iteration %d: expected NotSatisfiable, got %v for input %#v with globals %v", i, err, variables, globals)
+			}
+			continue
+		}
+		require.NoError(t, err, "iteration %d", i)
+
+		ids := []Identifier{}
+		for _, variable := range installed {
+			ids = append(ids, variable.Identifier())
+		}
+		assert.Contains(t, expected, ids, "iteration %d: input %#v with globals %v", i, variables, globals)
+	}
+}
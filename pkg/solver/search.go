@@ -2,6 +2,7 @@ package solver
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-air/gini/inter"
 	"github.com/go-air/gini/z"
@@ -11,6 +12,7 @@ type choice struct {
 	prev, next *choice
 	index      int // index of next unguessed literal
 	candidates []z.Lit
+	source     AppliedConstraint // constraint that introduced this choice
 }
 
 type guess struct {
@@ -18,10 +20,11 @@ type guess struct {
 	index      int   // index of guessed literal in candidates
 	children   int   // number of choices introduced by making this guess
 	candidates []z.Lit
+	source     AppliedConstraint
 }
 
 type search struct {
-	s                      inter.S
+	s                      Backend
 	lits                   *litMapping
 	assumptions            map[z.Lit]struct{} // set of assumed lits - duplicates guess stack - for fast lookup
 	guesses                []guess            // stack of assumed guesses
@@ -29,6 +32,9 @@ type search struct {
 	tracer                 Tracer
 	result                 int
 	buffer                 []z.Lit
+	model                  map[z.Lit]struct{} // set of true lits in the last satisfying assignment
+	guessed                map[z.Lit]guess    // guesses made in the last satisfying assignment
+	stats                  *Statistics        // if not nil, receives counts of guesses and backtracks
 }
 
 func (h *search) PushGuess() {
@@ -37,6 +43,7 @@ func (h *search) PushGuess() {
 		m:          z.LitNull,
 		index:      c.index,
 		candidates: c.candidates,
+		source:     c.source,
 	}
 	if g.index < len(g.candidates) {
 		g.m = g.candidates[g.index]
@@ -57,14 +64,17 @@ func (h *search) PushGuess() {
 	}
 
 	variable := h.lits.VariableOf(g.m)
-	for _, constraint := range variable.Constraints() {
+	for _, constraint := range h.lits.ActiveConstraints(variable) {
 		var ms []z.Lit
-		for _, dependency := range constraint.order() {
+		for _, dependency := range constraint.Order() {
 			ms = append(ms, h.lits.LitOf(dependency))
 		}
 		if len(ms) > 0 {
 			h.guesses[len(h.guesses)-1].children++
-			h.PushChoiceBack(choice{candidates: ms})
+			h.PushChoiceBack(choice{
+				candidates: ms,
+				source:     AppliedConstraint{Variable: variable, Constraint: constraint},
+			})
 		}
 	}
 
@@ -72,6 +82,9 @@ func (h *search) PushGuess() {
 		h.assumptions = make(map[z.Lit]struct{})
 	}
 	h.assumptions[g.m] = struct{}{}
+	if h.stats != nil {
+		h.stats.Guesses++
+	}
 	h.s.Assume(g.m)
 	h.result, h.buffer = h.s.Test(h.buffer)
 }
@@ -90,6 +103,7 @@ func (h *search) PopGuess() {
 	c := choice{
 		index:      g.index,
 		candidates: g.candidates,
+		source:     g.source,
 	}
 	if g.m != z.LitNull {
 		c.index++
@@ -157,15 +171,38 @@ func (h *search) Lits() []z.Lit {
 
 func (h *search) Do(ctx context.Context, anchors []z.Lit) (int, []z.Lit, map[z.Lit]struct{}) {
 	for _, m := range anchors {
-		h.PushChoiceBack(choice{candidates: []z.Lit{m}})
+		h.PushChoiceBack(choice{candidates: []z.Lit{m}, source: h.lits.AnchorOf(m)})
+	}
+
+	// Global constraints behave as if they applied to a Variable
+	// that is always selected, so their candidates are chosen
+	// right after the anchors.
+	for _, constraint := range h.lits.ActiveGlobalConstraints() {
+		var ms []z.Lit
+		for _, dependency := range constraint.Order() {
+			ms = append(ms, h.lits.LitOf(dependency))
+		}
+		if len(ms) > 0 {
+			h.PushChoiceBack(choice{
+				candidates: ms,
+				source:     AppliedConstraint{Constraint: constraint},
+			})
+		}
 	}
 
 	for {
+		// Give up as soon as the context is done, leaving the
+		// result unknown.
+		if ctx.Err() != nil {
+			h.result = unknown
+			break
+		}
+
 		// Need to have a definitive result once all choices
 		// have been made to decide whether to end or
 		// backtrack.
 		if h.headChoice == nil && h.result == unknown {
-			h.result = h.s.Solve()
+			h.result = solve(ctx, h.s)
 		}
 
 		// Backtrack if possible, otherwise end.
@@ -174,6 +211,9 @@ func (h *search) Do(ctx context.Context, anchors []z.Lit) (int, []z.Lit, map[z.L
 			if len(h.guesses) == 0 {
 				break
 			}
+			if h.stats != nil {
+				h.stats.Backtracks++
+			}
 			h.PopGuess()
 			continue
 		}
@@ -194,6 +234,23 @@ func (h *search) Do(ctx context.Context, anchors []z.Lit) (int, []z.Lit, map[z.L
 	}
 	result := h.Result()
 
+	// Record the satisfying assignment, since it is lost once
+	// the test scopes that produced it are popped.
+	if result == satisfiable {
+		h.model = make(map[z.Lit]struct{})
+		for _, m := range h.lits.Lits(nil) {
+			if h.s.Value(m) {
+				h.model[m] = struct{}{}
+			}
+		}
+		h.guessed = make(map[z.Lit]guess, len(h.guesses))
+		for _, g := range h.guesses {
+			if g.m != z.LitNull {
+				h.guessed[g.m] = g
+			}
+		}
+	}
+
 	// Go back to the initial test scope.
 	for len(h.guesses) > 0 {
 		h.PopGuess()
@@ -202,6 +259,50 @@ func (h *search) Do(ctx context.Context, anchors []z.Lit) (int, []z.Lit, map[z.L
 	return result, lits, set
 }
 
+// solvePollInterval is the interval at which an in-progress call to
+// solve checks whether the underlying solver has produced a result.
+const solvePollInterval = time.Millisecond
+
+// solve behaves like s.Solve, except that it returns unknown if the
+// provided Context is done before a result is available. The
+// underlying solver is only stopped early if it is an
+// inter.GoSolvable.
+func solve(ctx context.Context, s Backend) int {
+	if ctx.Done() == nil {
+		// The Context can never be cancelled, so there is no
+		// need to run the solver in the background.
+		return s.Solve()
+	}
+	if ctx.Err() != nil {
+		return unknown
+	}
+	gsv, ok := s.(inter.GoSolvable)
+	if !ok {
+		return s.Solve()
+	}
+
+	gs := gsv.GoSolve()
+	ticker := time.NewTicker(solvePollInterval)
+	defer ticker.Stop()
+	for {
+		if result, ok := gs.Test(); ok {
+			return result
+		}
+		select {
+		case <-ctx.Done():
+			return gs.Stop()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Value returns the value of the provided literal in the satisfying
+// assignment found by the most recent call to Do.
+func (h *search) Value(m z.Lit) bool {
+	_, ok := h.model[m]
+	return ok
+}
+
 func (h *search) Variables() []Variable {
 	result := make([]Variable, 0, len(h.guesses))
 	for _, g := range h.guesses {
@@ -104,3 +104,32 @@ func TestSearch(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchCancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	var s FakeS
+	var depth int
+	counter := &TestScopeCounter{depth: &depth, S: &s}
+
+	lits, err := newLitMapping([]Variable{
+		variable("a", Mandatory(), Dependency("x")),
+		variable("x"),
+	})
+	assert.NoError(err)
+	h := search{
+		s:      counter,
+		lits:   lits,
+		tracer: DefaultTracer{},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, ms, _ := h.Do(ctx, []z.Lit{lits.LitOf("a")})
+
+	assert.Equal(unknown, result)
+	assert.Empty(ms)
+	assert.Equal(0, s.SolveCallCount())
+	assert.Equal(0, s.GoSolveCallCount())
+	assert.Equal(0, depth)
+}
@@ -0,0 +1,92 @@
+package solver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-air/gini/z"
+)
+
+// ErrNoMoreSolutions is returned by SolutionIterator.Next once every
+// solution has been produced, or once the iterator's limit has been
+// reached.
+var ErrNoMoreSolutions = errors.New("no more solutions")
+
+// SolutionIterator produces successive distinct solutions to a
+// problem.
+type SolutionIterator interface {
+	// Next returns the next solution. The first call returns the
+	// same solution as Solve, and each later call returns the
+	// best remaining solution under the same preference order
+	// and cardinality minimization. If the problem has no
+	// solution at all, the first call returns NotSatisfiable;
+	// afterward, ErrNoMoreSolutions is returned once solutions
+	// are exhausted. ErrIncomplete is returned if the provided
+	// Context is done before a solution is found, in which case
+	// Next may be called again.
+	Next(ctx context.Context) ([]Variable, error)
+}
+
+type solutionIterator struct {
+	s     *solver
+	guard z.Lit // only assumed by Next, so that exclusions are local to it
+	limit int
+	count int
+	done  bool
+}
+
+// Solutions returns a SolutionIterator over the solutions to the
+// problem, producing at most limit solutions if limit is positive.
+//
+// Once returned, a solution is excluded from further consideration
+// by the iterator together with every solution that selects all of
+// its Variables, since such a solution differs from it only by
+// unnecessary selections.
+func (s *solver) Solutions(limit int) SolutionIterator {
+	return &solutionIterator{s: s, guard: s.litMap.c.Lit(), limit: limit}
+}
+
+func (it *solutionIterator) Next(ctx context.Context) (result []Variable, err error) {
+	defer func() {
+		// This likely indicates a bug, so discard whatever
+		// return values were produced.
+		if derr := it.s.litMap.Error(); derr != nil {
+			result = nil
+			err = derr
+		}
+	}()
+
+	if it.done || (it.limit > 0 && it.count >= it.limit) {
+		return nil, ErrNoMoreSolutions
+	}
+
+	it.s.prepare()
+	it.s.litMap.Require([]z.Lit{it.guard})
+	solution, err := it.s.solve(ctx)
+	it.s.litMap.Require(nil)
+	var ns NotSatisfiable
+	switch {
+	case errors.As(err, &ns):
+		if it.count > 0 {
+			err = ErrNoMoreSolutions
+		}
+		it.done = true
+		return nil, err
+	case err != nil:
+		return nil, err
+	}
+
+	it.count++
+	it.s.block(it.guard, solution.Selected)
+	return solution.Selected, nil
+}
+
+// block excludes every solution that selects all of the provided
+// Variables whenever guard is assumed.
+func (s *solver) block(guard z.Lit, selected []Variable) {
+	s.g.Add(guard.Not())
+	for _, variable := range selected {
+		s.g.Add(s.litMap.LitOf(variable.Identifier()).Not())
+	}
+	s.g.Add(z.LitNull)
+}
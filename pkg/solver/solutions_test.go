@@ -0,0 +1,141 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolutions(t *testing.T) {
+	type tc struct {
+		Name      string
+		Variables []Variable
+		Limit     int
+		Solutions [][]Identifier
+		Error     error
+	}
+
+	for _, tt := range []tc{
+		{
+			Name:      "no variables",
+			Solutions: [][]Identifier{nil},
+			Error:     ErrNoMoreSolutions,
+		},
+		{
+			Name: "alternatives in preference order",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y", "z")),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+			},
+			Solutions: [][]Identifier{
+				{"a", "x"},
+				{"a", "y"},
+				{"a", "z"},
+			},
+			Error: ErrNoMoreSolutions,
+		},
+		{
+			Name: "limit",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y", "z")),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+			},
+			Limit: 2,
+			Solutions: [][]Identifier{
+				{"a", "x"},
+				{"a", "y"},
+			},
+			Error: ErrNoMoreSolutions,
+		},
+		{
+			Name: "combined choices",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("b", Mandatory(), Dependency("y", "x")),
+				variable("x"),
+				variable("y"),
+			},
+			Solutions: [][]Identifier{
+				{"a", "b", "x"},
+				{"a", "b", "y"},
+			},
+			Error: ErrNoMoreSolutions,
+		},
+		{
+			Name: "not satisfiable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Prohibited()),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory(), Prohibited()),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("a", Mandatory(), Prohibited()),
+					Constraint: Prohibited(),
+				},
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s, err := New(WithInput(tt.Variables))
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			it := s.Solutions(tt.Limit)
+			var solutions [][]Identifier
+			for {
+				installed, err := it.Next(context.TODO())
+				if err != nil {
+					var ns NotSatisfiable
+					if errors.As(err, &ns) {
+						sortNotSatisfiable(ns)
+					}
+					assert.Equal(tt.Error, err)
+					break
+				}
+				var ids []Identifier
+				for _, variable := range installed {
+					ids = append(ids, variable.Identifier())
+				}
+				solutions = append(solutions, ids)
+			}
+			assert.Equal(tt.Solutions, solutions)
+
+			_, err = it.Next(context.TODO())
+			if len(tt.Solutions) > 0 {
+				assert.Equal(ErrNoMoreSolutions, err)
+			}
+
+			// Exclusions made by the iterator do not affect
+			// other calls.
+			if len(tt.Solutions) > 0 {
+				installed, err := s.Solve(context.TODO())
+				assert.NoError(err)
+				var ids []Identifier
+				for _, variable := range installed {
+					ids = append(ids, variable.Identifier())
+				}
+				assert.Equal(tt.Solutions[0], ids)
+
+				installed, err = s.Solutions(0).Next(context.TODO())
+				assert.NoError(err)
+				ids = nil
+				for _, variable := range installed {
+					ids = append(ids, variable.Identifier())
+				}
+				assert.Equal(tt.Solutions[0], ids)
+			}
+		})
+	}
+}
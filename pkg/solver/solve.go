@@ -0,0 +1,487 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/go-air/gini"
+	"github.com/go-air/gini/z"
+)
+
+// ErrIncomplete is returned by Solve when the provided Context is
+// cancelled or its deadline is exceeded before a result is found.
+var ErrIncomplete = errors.New("cancelled before a solution could be found")
+
+// NotSatisfiable is an error composed of a set of applied constraints
+// that is sufficient to make a solution impossible. The set is
+// guaranteed to be minimal, in that removing any one of its
+// constraints would make a solution possible, only if the Solver was
+// constructed using WithMinimalConflicts.
+type NotSatisfiable []AppliedConstraint
+
+func (e NotSatisfiable) Error() string {
+	const msg = "constraints not satisfiable"
+	if len(e) == 0 {
+		return msg
+	}
+	s := make([]string, len(e))
+	for i, a := range e {
+		s[i] = a.String()
+	}
+	return fmt.Sprintf("%s: %s", msg, strings.Join(s, ", "))
+}
+
+// Result describes a solution found by a Solver.
+type Result struct {
+	// Selected contains the Variables selected for
+	// installation, in input order.
+	Selected []Variable
+	// Violated contains the applications of soft Constraints
+	// that do not hold in the solution.
+	Violated []AppliedConstraint
+	// Objectives contains the value achieved for each Objective
+	// passed to WithObjectives, in the same order.
+	Objectives []ObjectiveValue
+	// ImplicitObjectives contains the values achieved for the
+	// objectives optimized before those passed to WithObjectives:
+	// the total weight of violated soft Constraints, if there are
+	// any, followed by the number of changes from the previous
+	// solution, if WithPreviousSolution is used.
+	ImplicitObjectives []ObjectiveValue
+	// Justifications explains the selection of each Variable in
+	// Selected, in the same order.
+	Justifications []Justification
+	// Statistics describes the work done to find the solution.
+	// Unlike the other fields, it is also populated when no
+	// solution is found.
+	Statistics Statistics
+}
+
+// Statistics describes the work done by a Solver during a single
+// call.
+type Statistics struct {
+	// Variables is the number of variables in the encoding of
+	// the problem, including auxiliary variables.
+	Variables int
+	// Clauses is the number of clauses taught to the Backend,
+	// including those taught by previous calls.
+	Clauses int
+	// Guesses is the number of guesses made by search.
+	Guesses int
+	// Backtracks is the number of guesses undone by search
+	// because they did not lead to a solution.
+	Backtracks int
+	// SolveCalls and TestCalls are the number of calls to the
+	// Solve and Test methods of the Backend.
+	SolveCalls int
+	TestCalls  int
+	// MinimizationIterations is the number of bounds tried while
+	// minimizing the number of Variables selected beyond those
+	// guessed by search.
+	MinimizationIterations int
+	// EncodeTime is the time spent encoding the problem, which is
+	// zero if it was already encoded by a previous call.
+	EncodeTime time.Duration
+	// OptimizeTime is the time spent optimizing Objectives,
+	// including soft Constraints and changes from a previous
+	// solution.
+	OptimizeTime time.Duration
+	// SearchTime is the time spent searching for a solution in
+	// order of preference.
+	SearchTime time.Duration
+	// MinimizeTime is the time spent minimizing the number of
+	// Variables selected.
+	MinimizeTime time.Duration
+	// ConflictTime is the time spent collecting, and if
+	// WithMinimalConflicts is used minimizing, the applied
+	// constraints of a NotSatisfiable error.
+	ConflictTime time.Duration
+}
+
+// Solver finds solutions to the problem it was constructed with.
+type Solver interface {
+	Solve(context.Context) ([]Variable, error)
+	SolveResult(context.Context) (Result, error)
+	SolveAssuming(ctx context.Context, assumptions ...Assumption) ([]Variable, error)
+	SolveResultAssuming(ctx context.Context, assumptions ...Assumption) (Result, error)
+	Solutions(limit int) SolutionIterator
+	Corrections(ctx context.Context, limit int, relaxable func(AppliedConstraint) bool) ([]Correction, error)
+	WhyNot(ctx context.Context, id Identifier) (Counterfactual, error)
+	AddVariables(variables ...Variable) error
+	RemoveVariables(ids ...Identifier) error
+	SetGlobalConstraints(constraints ...Constraint)
+}
+
+type solver struct {
+	g          Backend
+	counter    *counter
+	litMap     *litMapping
+	globals    []Constraint
+	tracer     Tracer
+	buffer     []z.Lit
+	prepared   bool
+	previous   []Identifier
+	objectives []Objective
+	optimized  []objective
+
+	minimalConflicts bool
+}
+
+// objective pairs an Objective with the encoding of the weighted sum
+// of its terms.
+type objective struct {
+	Objective
+	sum      *weightedSum
+	implicit bool // not passed to WithObjectives
+}
+
+const (
+	satisfiable   = 1
+	unsatisfiable = -1
+	unknown       = 0
+)
+
+// Solve takes a slice containing all Variables and returns a slice
+// containing only those Variables that were selected for
+// installation. If no solution is possible, or if the provided
+// Context times out or is cancelled, an error is returned.
+func (s *solver) Solve(ctx context.Context) ([]Variable, error) {
+	result, err := s.SolveResult(ctx)
+	return result.Selected, err
+}
+
+// SolveResult behaves like Solve, but returns a Result describing
+// the solution in more detail.
+func (s *solver) SolveResult(ctx context.Context) (result Result, err error) {
+	defer func() {
+		// This likely indicates a bug, so discard whatever
+		// return values were produced.
+		if derr := s.litMap.Error(); derr != nil {
+			result = Result{}
+			err = derr
+		}
+	}()
+
+	encode := s.prepare()
+	result, err = s.solve(ctx)
+	result.Statistics.EncodeTime = encode
+	return result, err
+}
+
+// prepare teaches all constraints to the solver, if that has not
+// already been done by a previous call since the problem was last
+// modified, and returns the time taken to do so.
+func (s *solver) prepare() time.Duration {
+	if s.prepared {
+		return 0
+	}
+	start := time.Now()
+	s.litMap.AddConstraints(s.g)
+	s.optimized = nil
+
+	// soft constraints take priority over every other
+	// objective, followed by changes from a previous solution
+	var objectives []Objective
+	if terms := s.litMap.ViolationTerms(); len(terms) > 0 {
+		objectives = append(objectives, violations(terms))
+	}
+	if s.previous != nil {
+		objectives = append(objectives, MinimizeChanges(s.previous))
+	}
+	for i, o := range append(objectives, s.objectives...) {
+		s.optimized = append(s.optimized, objective{
+			Objective: o,
+			sum:       newWeightedSum(s.litMap.c, objectiveTerms(o, s.litMap.c, s.litMap), math.MaxInt),
+			implicit:  i < len(objectives),
+		})
+	}
+	s.prepared = true
+	return time.Since(start)
+}
+
+// minimize returns a literal that bounds sum to the minimum value
+// achievable under the given assumptions, together with that minimum.
+// The minimum is found by binary search, starting from the value of
+// an unbounded solution. If no solution exists regardless of sum, it
+// returns z.LitNull.
+func (s *solver) minimize(ctx context.Context, sum *weightedSum, assumptions []z.Lit) (z.Lit, int, error) {
+	try := func(bound z.Lit) int {
+		s.litMap.Encode(s.g, bound)
+		s.litMap.AssumeConstraints(s.g)
+		s.g.Assume(assumptions...)
+		s.g.Assume(bound)
+		return solve(ctx, s.g)
+	}
+
+	switch try(s.litMap.c.T) {
+	case unsatisfiable:
+		return z.LitNull, 0, nil
+	case unknown:
+		return z.LitNull, 0, ErrIncomplete
+	}
+	hi := sum.Value(s.g.Value)
+	for lo := 0; lo < hi; {
+		k := lo + (hi-lo)/2
+		switch try(sum.Leq(k)) {
+		case satisfiable:
+			hi = sum.Value(s.g.Value)
+		case unsatisfiable:
+			lo = k + 1
+		default:
+			return z.LitNull, 0, ErrIncomplete
+		}
+	}
+	bound := sum.Leq(hi)
+	s.litMap.Encode(s.g, bound)
+	return bound, hi, nil
+}
+
+// solve finds a single solution to the problem taught to the solver
+// by prepare. The underlying solver is returned to its initial test
+// scope before solve returns, so that it may be called repeatedly.
+func (s *solver) solve(ctx context.Context) (result Result, err error) {
+	var stats Statistics
+	solves, tests := s.counter.solves, s.counter.tests
+	defer func() {
+		stats.Variables = s.litMap.c.Len() - 1
+		stats.Clauses = s.counter.clauses
+		stats.SolveCalls = s.counter.solves - solves
+		stats.TestCalls = s.counter.tests - tests
+		result.Statistics = stats
+	}()
+
+	// collect literals of all mandatory variables to assume as a baseline
+	assumptions := []z.Lit{}
+	for _, anchor := range s.litMap.AnchorIdentifiers() {
+		assumptions = append(assumptions, s.litMap.LitOf(anchor))
+	}
+
+	// objectives take priority over preference and cardinality,
+	// so fix each of their optimal values in turn first
+	var bounds []z.Lit
+	var values, implicit []ObjectiveValue
+	start := time.Now()
+	for _, o := range s.optimized {
+		bound, value, err := s.minimize(ctx, o.sum, append(assumptions, bounds...))
+		if err != nil {
+			return Result{}, err
+		}
+		if bound == z.LitNull {
+			// There is no solution, which is reported
+			// below.
+			break
+		}
+		bounds = append(bounds, bound)
+		if o.implicit {
+			implicit = append(implicit, ObjectiveValue{Objective: o.Objective, Value: value})
+			continue
+		}
+		values = append(values, ObjectiveValue{Objective: o.Objective, Value: value})
+	}
+	stats.OptimizeTime = time.Since(start)
+
+	// assume that all constraints hold
+	s.litMap.AssumeConstraints(s.g)
+	s.g.Assume(assumptions...)
+	s.g.Assume(bounds...)
+
+	var aset map[z.Lit]struct{}
+	var guessed map[z.Lit]guess
+	value := s.g.Value
+	start = time.Now()
+	// push a new test scope with the baseline assumptions, to prevent them from being cleared during search
+	outcome, _ := s.g.Test(nil)
+	if outcome != satisfiable && outcome != unsatisfiable {
+		// searcher for solutions in input order, so that preferences
+		// can be taken into acount (i.e. prefer one catalog to another)
+		h := search{s: s.g, lits: s.litMap, tracer: s.tracer, stats: &stats}
+		outcome, assumptions, aset = h.Do(ctx, assumptions)
+		value = h.Value
+		guessed = h.guessed
+	}
+	stats.SearchTime = time.Since(start)
+	start = time.Now()
+	switch outcome {
+	case satisfiable:
+		defer func() {
+			stats.MinimizeTime = time.Since(start)
+		}()
+		s.buffer = s.litMap.Lits(s.buffer)
+		var extras, excluded []z.Lit
+		for _, m := range s.buffer {
+			if _, ok := aset[m]; ok {
+				continue
+			}
+			if !value(m) {
+				excluded = append(excluded, m.Not())
+				continue
+			}
+			extras = append(extras, m)
+		}
+		s.g.Untest()
+		cs := s.litMap.CardinalityConstrainer(s.g, extras)
+		s.g.Assume(assumptions...)
+		s.g.Assume(excluded...)
+		s.g.Assume(bounds...)
+		s.litMap.AssumeConstraints(s.g)
+		_, s.buffer = s.g.Test(s.buffer)
+		defer s.g.Untest()
+		for w := 0; w <= cs.N(); w++ {
+			stats.MinimizationIterations++
+			s.g.Assume(cs.Leq(w))
+			switch solve(ctx, s.g) {
+			case satisfiable:
+				selected := s.litMap.Variables(s.g)
+				return Result{
+					Selected:           selected,
+					Violated:           s.litMap.Violations(s.g),
+					Objectives:         values,
+					ImplicitObjectives: implicit,
+					Justifications:     s.justify(selected, guessed),
+				}, nil
+			case unknown:
+				return Result{}, ErrIncomplete
+			}
+		}
+		// Something is wrong if we can't find a model anymore
+		// after optimizing for cardinality.
+		return Result{}, fmt.Errorf("unexpected internal error")
+	case unsatisfiable:
+		defer func() {
+			stats.ConflictTime = time.Since(start)
+		}()
+		core := s.litMap.ConflictLits(s.g)
+		s.g.Untest()
+		if s.minimalConflicts {
+			core = s.minimizeConflicts(ctx, core)
+		}
+		return Result{}, NotSatisfiable(s.litMap.ConstraintsOf(core))
+	}
+
+	s.g.Untest()
+	return Result{}, ErrIncomplete
+}
+
+// New returns a Solver configured by the provided Options.
+func New(options ...Option) (Solver, error) {
+	var s solver
+	for _, option := range append(options, defaults...) {
+		if err := option(&s); err != nil {
+			return nil, err
+		}
+	}
+	return &s, nil
+}
+
+// Option configures a Solver during construction by New.
+type Option func(s *solver) error
+
+// WithInput returns an Option that sets the Variables of the problem
+// to be solved. The order of the input determines search preference.
+// Each Variable must have a distinct, non-empty Identifier.
+func WithInput(input []Variable) Option {
+	return func(s *solver) error {
+		var err error
+		s.litMap, err = newLitMapping(input)
+		return err
+	}
+}
+
+// WithTracer returns an Option that sets the Tracer to be notified
+// of each backtracking step taken during search.
+func WithTracer(t Tracer) Option {
+	return func(s *solver) error {
+		s.tracer = t
+		return nil
+	}
+}
+
+// WithGlobalConstraints returns an Option that adds Constraints that
+// apply to the problem as a whole rather than to any one Variable.
+// Each behaves as if applied to a Variable that is present in every
+// solution, so that, for example, AtMost limits the selection
+// unconditionally and Dependency requires at least one of its
+// candidates. Global Constraints appear in NotSatisfiable errors
+// with a nil Variable. In particular, a global Mandatory always
+// holds, and a global Prohibited permits no solution at all.
+func WithGlobalConstraints(constraints ...Constraint) Option {
+	return func(s *solver) error {
+		s.globals = append(s.globals, constraints...)
+		return nil
+	}
+}
+
+// WithPreviousSolution returns an Option that identifies the
+// Variables selected by a previous solution to a similar problem.
+// Among the solutions that minimize the total weight of violated soft
+// Constraints, Solve then prefers those that differ from the previous
+// selection by the fewest Variables added or removed, before
+// considering search preference or cardinality. This is equivalent
+// to the Objective returned by MinimizeChanges, optimized before any
+// passed to WithObjectives, and its value is reported in
+// Result.ImplicitObjectives.
+func WithPreviousSolution(ids []Identifier) Option {
+	return func(s *solver) error {
+		s.previous = make([]Identifier, len(ids))
+		copy(s.previous, ids)
+		return nil
+	}
+}
+
+// WithObjectives returns an Option that adds Objectives to be
+// optimized, in the given order, after the total weight of violated
+// soft Constraints and, if WithPreviousSolution is used, the number
+// of changes from the previous solution. The values achieved are
+// reported in Result.Objectives, in the same order.
+func WithObjectives(objectives ...Objective) Option {
+	return func(s *solver) error {
+		s.objectives = append(s.objectives, objectives...)
+		return nil
+	}
+}
+
+// WithMinimalConflicts returns an Option that causes NotSatisfiable
+// errors to contain a minimal set of applied constraints, from which
+// no constraint can be removed without making a solution possible.
+// Minimization requires additional calls to the underlying solver,
+// and stops early, returning a set that may not be minimal, when the
+// Context passed to Solve is done.
+func WithMinimalConflicts() Option {
+	return func(s *solver) error {
+		s.minimalConflicts = true
+		return nil
+	}
+}
+
+var defaults = []Option{
+	func(s *solver) error {
+		if s.g == nil {
+			s.g = gini.New()
+		}
+		s.counter = &counter{Backend: s.g}
+		s.g = s.counter
+		return nil
+	},
+	func(s *solver) error {
+		if s.litMap == nil {
+			var err error
+			s.litMap, err = newLitMapping(nil)
+			return err
+		}
+		return nil
+	},
+	func(s *solver) error {
+		s.litMap.AddGlobalConstraints(s.globals)
+		return nil
+	},
+	func(s *solver) error {
+		if s.tracer == nil {
+			s.tracer = DefaultTracer{}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,1037 @@
+package solver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-air/gini"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestVariable struct {
+	identifier  Identifier
+	constraints []Constraint
+}
+
+func (i TestVariable) Identifier() Identifier {
+	return i.identifier
+}
+
+func (i TestVariable) Constraints() []Constraint {
+	return i.constraints
+}
+
+func (i TestVariable) GoString() string {
+	return fmt.Sprintf("%q", i.Identifier())
+}
+
+func variable(id Identifier, constraints ...Constraint) Variable {
+	return TestVariable{
+		identifier:  id,
+		constraints: constraints,
+	}
+}
+
+func TestNotSatisfiableError(t *testing.T) {
+	type tc struct {
+		Name   string
+		Error  NotSatisfiable
+		String string
+	}
+
+	for _, tt := range []tc{
+		{
+			Name:   "nil",
+			String: "constraints not satisfiable",
+		},
+		{
+			Name:   "empty",
+			String: "constraints not satisfiable",
+			Error:  NotSatisfiable{},
+		},
+		{
+			Name: "single failure",
+			Error: NotSatisfiable{
+				AppliedConstraint{
+					Variable:   variable("a", Mandatory()),
+					Constraint: Mandatory(),
+				},
+			},
+			String: fmt.Sprintf("constraints not satisfiable: %s",
+				Mandatory().String("a")),
+		},
+		{
+			Name: "multiple failures",
+			Error: NotSatisfiable{
+				AppliedConstraint{
+					Variable:   variable("a", Mandatory()),
+					Constraint: Mandatory(),
+				},
+				AppliedConstraint{
+					Variable:   variable("b", Prohibited()),
+					Constraint: Prohibited(),
+				},
+			},
+			String: fmt.Sprintf("constraints not satisfiable: %s, %s",
+				Mandatory().String("a"), Prohibited().String("b")),
+		},
+		{
+			Name: "global constraint",
+			Error: NotSatisfiable{
+				AppliedConstraint{
+					Constraint: AtMost(1, "a", "b"),
+				},
+			},
+			String: "constraints not satisfiable: at most 1 of a, b are permitted",
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.String, tt.Error.Error())
+		})
+	}
+}
+
+func TestSolve(t *testing.T) {
+	type tc struct {
+		Name      string
+		Variables []Variable
+		Installed []Identifier
+		Error     error
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "no variables",
+		},
+		{
+			Name:      "unnecessary variable is not installed",
+			Variables: []Variable{variable("a")},
+		},
+		{
+			Name:      "single mandatory variable is installed",
+			Variables: []Variable{variable("a", Mandatory())},
+			Installed: []Identifier{"a"},
+		},
+		{
+			Name:      "both mandatory and prohibited produce error",
+			Variables: []Variable{variable("a", Mandatory(), Prohibited())},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory(), Prohibited()),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("a", Mandatory(), Prohibited()),
+					Constraint: Prohibited(),
+				},
+			},
+		},
+		{
+			Name: "dependency is installed",
+			Variables: []Variable{
+				variable("a"),
+				variable("b", Mandatory(), Dependency("a")),
+			},
+			Installed: []Identifier{"a", "b"},
+		},
+		{
+			Name: "transitive dependency is installed",
+			Variables: []Variable{
+				variable("a"),
+				variable("b", Dependency("a")),
+				variable("c", Mandatory(), Dependency("b")),
+			},
+			Installed: []Identifier{"a", "b", "c"},
+		},
+		{
+			Name: "both dependencies are installed",
+			Variables: []Variable{
+				variable("a"),
+				variable("b"),
+				variable("c", Mandatory(), Dependency("a"), Dependency("b")),
+			},
+			Installed: []Identifier{"a", "b", "c"},
+		},
+		{
+			Name: "solution with first dependency is selected",
+			Variables: []Variable{
+				variable("a"),
+				variable("b", Conflict("a")),
+				variable("c", Mandatory(), Dependency("a", "b")),
+			},
+			Installed: []Identifier{"a", "c"},
+		},
+		{
+			Name: "solution with only first dependency is selected",
+			Variables: []Variable{
+				variable("a"),
+				variable("b"),
+				variable("c", Mandatory(), Dependency("a", "b")),
+			},
+			Installed: []Identifier{"a", "c"},
+		},
+		{
+			Name: "solution with first dependency is selected (reverse)",
+			Variables: []Variable{
+				variable("a"),
+				variable("b", Conflict("a")),
+				variable("c", Mandatory(), Dependency("b", "a")),
+			},
+			Installed: []Identifier{"b", "c"},
+		},
+		{
+			Name: "two mandatory but conflicting packages",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+				variable("b", Mandatory(), Conflict("a")),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory()),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("b", Mandatory(), Conflict("a")),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("b", Mandatory(), Conflict("a")),
+					Constraint: Conflict("a"),
+				},
+			},
+		},
+		{
+			Name: "irrelevant dependencies don't influence search order",
+			Variables: []Variable{
+				variable("a", Dependency("x", "y")),
+				variable("b", Mandatory(), Dependency("y", "x")),
+				variable("x"),
+				variable("y"),
+			},
+			Installed: []Identifier{"b", "y"},
+		},
+		{
+			Name: "cardinality constraint prevents resolution",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y"), AtMost(1, "x", "y")),
+				variable("x", Mandatory()),
+				variable("y", Mandatory()),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory(), Dependency("x", "y"), AtMost(1, "x", "y")),
+					Constraint: AtMost(1, "x", "y"),
+				},
+				{
+					Variable:   variable("x", Mandatory()),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("y", Mandatory()),
+					Constraint: Mandatory(),
+				},
+			},
+		},
+		{
+			Name: "cardinality constraint forces alternative",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y"), AtMost(1, "x", "y")),
+				variable("b", Mandatory(), Dependency("y")),
+				variable("x"),
+				variable("y"),
+			},
+			Installed: []Identifier{"a", "b", "y"},
+		},
+		{
+			Name: "two dependencies satisfied by one variable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("y")),
+				variable("b", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Installed: []Identifier{"a", "b", "y"},
+		},
+		{
+			Name: "foo two dependencies satisfied by one variable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("y", "z", "m")),
+				variable("b", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+				variable("m"),
+			},
+			Installed: []Identifier{"a", "b", "y"},
+		},
+		{
+			Name: "result size larger than minimum due to preference",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("b", Mandatory(), Dependency("y")),
+				variable("x"),
+				variable("y"),
+			},
+			Installed: []Identifier{"a", "b", "x", "y"},
+		},
+		{
+			Name: "only the least preferable choice is acceptable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("a1", "a2")),
+				variable("a1", Conflict("c1"), Conflict("c2")),
+				variable("a2", Conflict("c1")),
+				variable("b", Mandatory(), Dependency("b1", "b2")),
+				variable("b1", Conflict("c1"), Conflict("c2")),
+				variable("b2", Conflict("c1")),
+				variable("c", Mandatory(), Dependency("c1", "c2")),
+				variable("c1"),
+				variable("c2"),
+			},
+			Installed: []Identifier{"a", "a2", "b", "b2", "c", "c2"},
+		},
+		{
+			Name: "at least constraint is satisfied",
+			Variables: []Variable{
+				variable("a", Mandatory(), AtLeast(2, "x", "y")),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+			},
+			Installed: []Identifier{"a", "x", "y"},
+		},
+		{
+			Name: "at least constraint does not apply to unselected subject",
+			Variables: []Variable{
+				variable("a", AtLeast(2, "x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+		},
+		{
+			Name: "at least constraint prevents resolution",
+			Variables: []Variable{
+				variable("a", Mandatory(), AtLeast(2, "x", "y")),
+				variable("x"),
+				variable("y", Prohibited()),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory(), AtLeast(2, "x", "y")),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("a", Mandatory(), AtLeast(2, "x", "y")),
+					Constraint: AtLeast(2, "x", "y"),
+				},
+				{
+					Variable:   variable("y", Prohibited()),
+					Constraint: Prohibited(),
+				},
+			},
+		},
+		{
+			Name: "candidate implied by propagation during search is installed",
+			Variables: []Variable{
+				variable("a", Mandatory(), AtLeast(2, "x", "y", "z")),
+				variable("x"),
+				variable("y"),
+				variable("z", Conflict("y")),
+			},
+			Installed: []Identifier{"a", "x", "z"},
+		},
+		{
+			Name: "dependency implied by propagation during search is installed",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x", And(Dependency("z"))),
+				variable("y", Conflict("x")),
+				variable("z"),
+			},
+			Installed: []Identifier{"a", "x", "z"},
+		},
+		{
+			Name: "exactly constraint selects one candidate",
+			Variables: []Variable{
+				variable("a", Mandatory(), Exactly(1, "x", "y"), Dependency("y")),
+				variable("x"),
+				variable("y"),
+			},
+			Installed: []Identifier{"a", "y"},
+		},
+		{
+			Name: "exactly constraint prevents resolution",
+			Variables: []Variable{
+				variable("a", Mandatory(), Exactly(1, "x", "y")),
+				variable("x", Mandatory()),
+				variable("y", Mandatory()),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory(), Exactly(1, "x", "y")),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("a", Mandatory(), Exactly(1, "x", "y")),
+					Constraint: Exactly(1, "x", "y"),
+				},
+				{
+					Variable:   variable("x", Mandatory()),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("y", Mandatory()),
+					Constraint: Mandatory(),
+				},
+			},
+		},
+		{
+			Name: "weighted sum forces cheaper alternative",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y"), WeightedAtMost(4, Term{"x", 5}, Term{"y", 3})),
+				variable("x"),
+				variable("y"),
+			},
+			Installed: []Identifier{"a", "y"},
+		},
+		{
+			Name: "weighted sum with negative coefficient",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x"), WeightedAtMost(1, Term{"x", 3}, Term{"y", -2})),
+				variable("x"),
+				variable("y"),
+			},
+			Installed: []Identifier{"a", "x", "y"},
+		},
+		{
+			Name: "implication installs consequence",
+			Variables: []Variable{
+				variable("a", Mandatory(), Implies(Selected("b"), Selected("c"))),
+				variable("b", Mandatory()),
+				variable("c"),
+			},
+			Installed: []Identifier{"a", "b", "c"},
+		},
+		{
+			Name: "implication with unmet condition does not install consequence",
+			Variables: []Variable{
+				variable("a", Mandatory(), Implies(And(Selected("a"), Selected("b")), Selected("c"))),
+				variable("b"),
+				variable("c"),
+			},
+			Installed: []Identifier{"a"},
+		},
+		{
+			Name: "conflict waived by disjunction",
+			Variables: []Variable{
+				variable("a", Mandatory(), Or(Selected("z"), And(Conflict("x"), Conflict("y")))),
+				variable("x", Mandatory()),
+				variable("y"),
+				variable("z"),
+			},
+			Installed: []Identifier{"a", "x", "z"},
+		},
+		{
+			Name: "negated combinator prevents resolution",
+			Variables: []Variable{
+				variable("a", Mandatory(), Not(Or(Selected("x"), Selected("y")))),
+				variable("x", Mandatory()),
+				variable("y"),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory(), Not(Or(Selected("x"), Selected("y")))),
+					Constraint: Not(Or(Selected("x"), Selected("y"))),
+				},
+				{
+					Variable:   variable("x", Mandatory()),
+					Constraint: Mandatory(),
+				},
+			},
+		},
+		{
+			Name: "preferences respected with multiple dependencies per variable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x1", "x2"), Dependency("y1", "y2")),
+				variable("x1"),
+				variable("x2"),
+				variable("y1"),
+				variable("y2"),
+			},
+			Installed: []Identifier{"a", "x1", "y1"},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var traces bytes.Buffer
+			s, err := New(WithInput(tt.Variables), WithTracer(LoggingTracer{Writer: &traces}))
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			installed, err := s.Solve(context.TODO())
+
+			if installed != nil {
+				sort.SliceStable(installed, func(i, j int) bool {
+					return installed[i].Identifier() < installed[j].Identifier()
+				})
+			}
+
+			var ns NotSatisfiable
+			if errors.As(err, &ns) {
+				sortNotSatisfiable(ns)
+			}
+
+			var ids []Identifier
+			for _, variable := range installed {
+				ids = append(ids, variable.Identifier())
+			}
+			assert.Equal(tt.Installed, ids)
+			assert.Equal(tt.Error, err)
+
+			if t.Failed() {
+				t.Logf("\n%s", traces.String())
+			}
+		})
+	}
+}
+
+// sortNotSatisfiable sorts failed constraints in lexically
+// increasing order of the identifier of the constraint's variable,
+// with ties broken in favor of the constraint that appears earliest
+// in the variable's list of constraints.
+func sortNotSatisfiable(ns NotSatisfiable) {
+	sort.SliceStable(ns, func(i, j int) bool {
+		if ns[i].Variable.Identifier() != ns[j].Variable.Identifier() {
+			return ns[i].Variable.Identifier() < ns[j].Variable.Identifier()
+		}
+		var x, y int
+		for ii, c := range ns[i].Variable.Constraints() {
+			if reflect.DeepEqual(c, ns[i].Constraint) {
+				x = ii
+				break
+			}
+		}
+		for ij, c := range ns[j].Variable.Constraints() {
+			if reflect.DeepEqual(c, ns[j].Constraint) {
+				y = ij
+				break
+			}
+		}
+		return x < y
+	})
+}
+
+func TestDuplicateIdentifier(t *testing.T) {
+	_, err := New(WithInput([]Variable{
+		variable("a"),
+		variable("a"),
+	}))
+	assert.Equal(t, DuplicateIdentifier("a"), err)
+}
+
+func TestEmptyIdentifier(t *testing.T) {
+	_, err := New(WithInput([]Variable{
+		variable("", Prohibited()),
+		variable("a", Mandatory()),
+	}))
+	assert.Equal(t, ErrEmptyIdentifier, err)
+}
+
+func TestSolveUnreferencedVariables(t *testing.T) {
+	// Variables that appear in no clause are unknown to the
+	// underlying solver unless the solver accounts for them.
+	variables := []Variable{variable("a", Mandatory())}
+	for i := 0; i < 300; i++ {
+		variables = append(variables, variable(Identifier(fmt.Sprintf("unreferenced-%d", i))))
+	}
+	s, err := New(WithInput(variables))
+	if err != nil {
+		t.Fatalf("failed to initialize solver: %s", err)
+	}
+	installed, err := s.Solve(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, variables[:1], installed)
+}
+
+func TestSolveContext(t *testing.T) {
+	type tc struct {
+		Name      string
+		Context   func() (context.Context, context.CancelFunc)
+		Installed []Identifier
+		Error     error
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "cancelled context",
+			Context: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			Error: ErrIncomplete,
+		},
+		{
+			Name: "deadline exceeded",
+			Context: func() (context.Context, context.CancelFunc) {
+				return context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			},
+			Error: ErrIncomplete,
+		},
+		{
+			Name: "deadline not exceeded",
+			Context: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), time.Minute)
+			},
+			Installed: []Identifier{"a", "b", "x"},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s, err := New(WithInput([]Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("b", Mandatory(), Dependency("y", "x")),
+				variable("x"),
+				variable("y", Conflict("x")),
+			}))
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			ctx, cancel := tt.Context()
+			defer cancel()
+			installed, err := s.Solve(ctx)
+
+			var ids []Identifier
+			for _, variable := range installed {
+				ids = append(ids, variable.Identifier())
+			}
+			assert.Equal(tt.Installed, ids)
+			assert.Equal(tt.Error, err)
+		})
+	}
+}
+
+func TestSolveGlobalConstraints(t *testing.T) {
+	type tc struct {
+		Name        string
+		Variables   []Variable
+		Constraints []Constraint
+		Installed   []Identifier
+		Error       error
+		Message     string
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "global cardinality constraint is respected",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("b", Mandatory(), Dependency("y")),
+				variable("x"),
+				variable("y"),
+			},
+			Constraints: []Constraint{AtMost(1, "x", "y")},
+			Installed:   []Identifier{"a", "b", "y"},
+		},
+		{
+			Name: "global dependency selects preferred candidate",
+			Variables: []Variable{
+				variable("x"),
+				variable("y"),
+			},
+			Constraints: []Constraint{Dependency("y", "x")},
+			Installed:   []Identifier{"y"},
+		},
+		{
+			Name: "global exactly constraint",
+			Variables: []Variable{
+				variable("x", Mandatory()),
+				variable("y"),
+				variable("z"),
+			},
+			Constraints: []Constraint{Exactly(2, "x", "y", "z"), Conflict("y")},
+			Installed:   []Identifier{"x", "z"},
+		},
+		{
+			Name: "global constraint reported without variable",
+			Variables: []Variable{
+				variable("x", Mandatory()),
+				variable("y", Mandatory()),
+			},
+			Constraints: []Constraint{AtMost(1, "x", "y")},
+			Error: NotSatisfiable{
+				{
+					Constraint: AtMost(1, "x", "y"),
+				},
+				{
+					Variable:   variable("x", Mandatory()),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("y", Mandatory()),
+					Constraint: Mandatory(),
+				},
+			},
+			Message: "constraints not satisfiable: at most 1 of x, y are permitted, x is mandatory, y is mandatory",
+		},
+		{
+			Name: "global conflict prohibits its variable",
+			Variables: []Variable{
+				variable("x", Mandatory()),
+			},
+			Constraints: []Constraint{Conflict("x")},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("x", Mandatory()),
+					Constraint: Mandatory(),
+				},
+				{
+					Constraint: Conflict("x"),
+				},
+			},
+			Message: "constraints not satisfiable: x is mandatory, x is prohibited",
+		},
+		{
+			Name: "global mandatory always holds",
+			Variables: []Variable{
+				variable("x"),
+			},
+			Constraints: []Constraint{Mandatory()},
+		},
+		{
+			Name: "global prohibited permits no solution",
+			Variables: []Variable{
+				variable("x"),
+			},
+			Constraints: []Constraint{Prohibited()},
+			Error: NotSatisfiable{
+				{
+					Constraint: Prohibited(),
+				},
+			},
+			Message: "constraints not satisfiable: no solution is permitted",
+		},
+		{
+			Name: "global dependency without candidates",
+			Variables: []Variable{
+				variable("x"),
+			},
+			Constraints: []Constraint{Dependency()},
+			Error: NotSatisfiable{
+				{
+					Constraint: Dependency(),
+				},
+			},
+			Message: "constraints not satisfiable: a dependency without any candidates to satisfy it is required",
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s, err := New(WithInput(tt.Variables), WithGlobalConstraints(tt.Constraints...))
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			installed, err := s.Solve(context.TODO())
+
+			var ids []Identifier
+			for _, variable := range installed {
+				ids = append(ids, variable.Identifier())
+			}
+			var ns NotSatisfiable
+			if errors.As(err, &ns) {
+				sort.SliceStable(ns, func(i, j int) bool {
+					return ns[i].String() < ns[j].String()
+				})
+			}
+			assert.Equal(tt.Installed, ids)
+			assert.Equal(tt.Error, err)
+			if tt.Message != "" {
+				assert.EqualError(err, tt.Message)
+			}
+		})
+	}
+}
+
+func TestSolveSoftConstraints(t *testing.T) {
+	type tc struct {
+		Name        string
+		Variables   []Variable
+		Constraints []Constraint
+		Installed   []Identifier
+		Violated    []AppliedConstraint
+		Error       error
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "soft constraint overrides preference",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x", Soft(1, Prohibited())),
+				variable("y"),
+			},
+			Installed: []Identifier{"a", "y"},
+		},
+		{
+			Name: "unavoidable violation is reported",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x")),
+				variable("x", Soft(2, Prohibited())),
+			},
+			Installed: []Identifier{"a", "x"},
+			Violated: []AppliedConstraint{
+				{
+					Variable:   variable("x", Soft(2, Prohibited())),
+					Constraint: Soft(2, Prohibited()),
+				},
+			},
+		},
+		{
+			Name: "least total weight is violated",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x", Soft(3, Prohibited())),
+				variable("y", Soft(1, Prohibited()), Soft(1, Dependency("z"))),
+				variable("z"),
+			},
+			Installed: []Identifier{"a", "y", "z"},
+			Violated: []AppliedConstraint{
+				{
+					Variable:   variable("y", Soft(1, Prohibited()), Soft(1, Dependency("z"))),
+					Constraint: Soft(1, Prohibited()),
+				},
+			},
+		},
+		{
+			Name: "large weights",
+			Variables: []Variable{
+				variable("a", Mandatory(), Soft(2999, Dependency("x")), Soft(3000, Dependency("y"))),
+				variable("x", Conflict("y")),
+				variable("y"),
+			},
+			Installed: []Identifier{"a", "y"},
+			Violated: []AppliedConstraint{
+				{
+					Variable:   variable("a", Mandatory(), Soft(2999, Dependency("x")), Soft(3000, Dependency("y"))),
+					Constraint: Soft(2999, Dependency("x")),
+				},
+			},
+		},
+		{
+			Name: "global soft constraint",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Constraints: []Constraint{Soft(1, Conflict("x"))},
+			Installed:   []Identifier{"a", "y"},
+		},
+		{
+			Name: "hard constraints are not relaxed",
+			Variables: []Variable{
+				variable("a", Mandatory(), Soft(1, Dependency("x")), Prohibited()),
+				variable("x"),
+			},
+			Error: NotSatisfiable{
+				{
+					Variable:   variable("a", Mandatory(), Soft(1, Dependency("x")), Prohibited()),
+					Constraint: Mandatory(),
+				},
+				{
+					Variable:   variable("a", Mandatory(), Soft(1, Dependency("x")), Prohibited()),
+					Constraint: Prohibited(),
+				},
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s, err := New(WithInput(tt.Variables), WithGlobalConstraints(tt.Constraints...))
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			result, err := s.SolveResult(context.TODO())
+
+			var ids []Identifier
+			for _, variable := range result.Selected {
+				ids = append(ids, variable.Identifier())
+			}
+			var ns NotSatisfiable
+			if errors.As(err, &ns) {
+				sortNotSatisfiable(ns)
+			}
+			assert.Equal(tt.Installed, ids)
+			assert.Equal(tt.Violated, result.Violated)
+			assert.Equal(tt.Error, err)
+		})
+	}
+}
+
+func TestSolvePreviousSolution(t *testing.T) {
+	type tc struct {
+		Name      string
+		Variables []Variable
+		Previous  []Identifier
+		Installed []Identifier
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "previously selected candidate is kept over preferred candidate",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x2", "x1")),
+				variable("x1"),
+				variable("x2"),
+			},
+			Previous:  []Identifier{"a", "x1"},
+			Installed: []Identifier{"a", "x1"},
+		},
+		{
+			Name: "preference applies without previous selection",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x2", "x1")),
+				variable("x1"),
+				variable("x2"),
+			},
+			Installed: []Identifier{"a", "x2"},
+		},
+		{
+			Name: "unneeded previously selected variable is kept",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+				variable("b"),
+			},
+			Previous:  []Identifier{"a", "b"},
+			Installed: []Identifier{"a", "b"},
+		},
+		{
+			Name: "fewest changes are made when previous selection is invalid",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("y", "x")),
+				variable("b", Mandatory(), Dependency("y", "z")),
+				variable("x", Conflict("b")),
+				variable("y"),
+				variable("z"),
+			},
+			Previous:  []Identifier{"a", "x", "gone"},
+			Installed: []Identifier{"a", "b", "y"},
+		},
+		{
+			Name: "empty previous selection minimizes additions",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("b", Mandatory(), Dependency("y")),
+				variable("x"),
+				variable("y"),
+			},
+			Previous:  []Identifier{},
+			Installed: []Identifier{"a", "b", "y"},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			options := []Option{WithInput(tt.Variables)}
+			if tt.Previous != nil {
+				options = append(options, WithPreviousSolution(tt.Previous))
+			}
+			s, err := New(options...)
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			installed, err := s.Solve(context.TODO())
+			assert.NoError(err)
+
+			var ids []Identifier
+			for _, variable := range installed {
+				ids = append(ids, variable.Identifier())
+			}
+			assert.Equal(tt.Installed, ids)
+		})
+	}
+}
+
+func TestSolveStatistics(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := New(WithInput([]Variable{
+		variable("a", Mandatory(), Dependency("x", "y")),
+		variable("x", Dependency("z")),
+		variable("y"),
+		variable("z", Conflict("x")),
+	}))
+	if err != nil {
+		t.Fatalf("failed to initialize solver: %s", err)
+	}
+
+	result, err := s.SolveResult(context.TODO())
+	assert.NoError(err)
+	stats := result.Statistics
+	assert.Equal(9, stats.Variables)
+	assert.Equal(14, stats.Clauses)
+	assert.Equal(3, stats.Guesses)
+	assert.Equal(1, stats.Backtracks)
+	assert.Equal(2, stats.SolveCalls)
+	assert.Equal(5, stats.TestCalls)
+	assert.Equal(1, stats.MinimizationIterations)
+	assert.Zero(stats.ConflictTime)
+
+	// The problem is only encoded once.
+	result, err = s.SolveResult(context.TODO())
+	assert.NoError(err)
+	assert.Zero(result.Statistics.EncodeTime)
+	assert.Equal(stats.Clauses, result.Statistics.Clauses)
+	assert.Equal(stats.Guesses, result.Statistics.Guesses)
+
+	// Calls are counted whether or not the Backend solves in
+	// the background.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, backend := range []Backend{gini.New(), &countingBackend{Backend: gini.New()}} {
+		s, err = New(WithInput([]Variable{
+			variable("a", Mandatory(), Dependency("x", "y")),
+			variable("x", Dependency("z")),
+			variable("y"),
+			variable("z", Conflict("x")),
+		}), WithBackend(backend))
+		if err != nil {
+			t.Fatalf("failed to initialize solver: %s", err)
+		}
+		result, err = s.SolveResult(ctx)
+		assert.NoError(err)
+		assert.Equal(stats.SolveCalls, result.Statistics.SolveCalls)
+		if b, ok := backend.(*countingBackend); ok {
+			assert.Equal(b.solves, result.Statistics.SolveCalls)
+		}
+	}
+
+	// Statistics are also reported without a solution.
+	s, err = New(WithInput([]Variable{
+		variable("a", Mandatory(), Prohibited()),
+	}))
+	if err != nil {
+		t.Fatalf("failed to initialize solver: %s", err)
+	}
+	result, err = s.SolveResult(context.TODO())
+	assert.IsType(NotSatisfiable{}, err)
+	assert.Nil(result.Selected)
+	assert.NotZero(result.Statistics.Variables)
+	assert.NotZero(result.Statistics.TestCalls)
+	assert.Zero(result.Statistics.MinimizationIterations)
+	assert.Zero(result.Statistics.MinimizeTime)
+}
@@ -5,20 +5,27 @@ import (
 	"io"
 )
 
+// SearchPosition describes the state of an in-progress search, as
+// observed by a Tracer.
 type SearchPosition interface {
 	Variables() []Variable
 	Conflicts() []AppliedConstraint
 }
 
+// Tracer implementations are notified whenever search encounters a
+// conflict and is about to backtrack.
 type Tracer interface {
 	Trace(p SearchPosition)
 }
 
+// DefaultTracer is a Tracer that does nothing.
 type DefaultTracer struct{}
 
 func (DefaultTracer) Trace(_ SearchPosition) {
 }
 
+// LoggingTracer is a Tracer that writes a description of each
+// SearchPosition to Writer.
 type LoggingTracer struct {
 	Writer io.Writer
 }
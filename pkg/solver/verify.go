@@ -0,0 +1,61 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/go-air/gini/z"
+)
+
+// Verify returns every applied constraint that does not hold when
+// exactly the Variables identified by selected are selected, without
+// performing a search. The Constraints of variables are checked in
+// input order, followed by the given global Constraints, which are
+// checked as if passed to WithGlobalConstraints. Soft Constraints are
+// checked like any other, and may be recognized in the result using
+// Inspect.
+//
+// Constraints are evaluated using the same encoding that Solve
+// relies on, so a selection returned by Solve never violates a hard
+// constraint. An error is returned if an Identifier in selected does
+// not identify one of variables.
+func Verify(variables []Variable, selected []Identifier, globals ...Constraint) ([]AppliedConstraint, error) {
+	d, err := newLitMapping(variables)
+	if err != nil {
+		return nil, err
+	}
+	d.AddGlobalConstraints(globals)
+	if err := d.Error(); err != nil {
+		return nil, err
+	}
+
+	vs := make([]bool, d.c.Len())
+	for _, id := range selected {
+		m, ok := d.lits[id]
+		if !ok {
+			return nil, fmt.Errorf("selected variable %q not provided", id)
+		}
+		vs[m.Var()] = true
+	}
+	d.c.Eval(vs)
+	value := func(m z.Lit) bool {
+		return vs[m.Var()] == m.IsPos()
+	}
+
+	var violated []AppliedConstraint
+	for _, variable := range d.inorder {
+		for i, m := range d.applied[variable.Identifier()] {
+			if m != z.LitNull && !value(m) {
+				violated = append(violated, AppliedConstraint{
+					Variable:   variable,
+					Constraint: variable.Constraints()[i],
+				})
+			}
+		}
+	}
+	for i, m := range d.globalLits {
+		if m != z.LitNull && !value(m) {
+			violated = append(violated, AppliedConstraint{Constraint: d.globals[i]})
+		}
+	}
+	return violated, nil
+}
@@ -0,0 +1,142 @@
+package solver
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	type tc struct {
+		Name        string
+		Variables   []Variable
+		Constraints []Constraint
+		Selected    []Identifier
+		Violated    []AppliedConstraint
+		Error       string
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "valid selection",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			Selected: []Identifier{"a", "y"},
+		},
+		{
+			Name: "every violation is reported",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x")),
+				variable("b", Mandatory(), Conflict("x")),
+				variable("x", Prohibited()),
+			},
+			Selected: []Identifier{"b", "x"},
+			Violated: []AppliedConstraint{
+				{Variable: variable("a", Mandatory(), Dependency("x")), Constraint: Mandatory()},
+				{Variable: variable("b", Mandatory(), Conflict("x")), Constraint: Conflict("x")},
+				{Variable: variable("x", Prohibited()), Constraint: Prohibited()},
+			},
+		},
+		{
+			Name: "global and soft constraints",
+			Variables: []Variable{
+				variable("a", Soft(1, Dependency("b"))),
+				variable("b"),
+			},
+			Constraints: []Constraint{AtMost(0, "a", "b")},
+			Selected:    []Identifier{"a"},
+			Violated: []AppliedConstraint{
+				{Variable: variable("a", Soft(1, Dependency("b"))), Constraint: Soft(1, Dependency("b"))},
+				{Constraint: AtMost(0, "a", "b")},
+			},
+		},
+		{
+			Name: "unknown selected variable",
+			Variables: []Variable{
+				variable("a"),
+			},
+			Selected: []Identifier{"b"},
+			Error:    `selected variable "b" not provided`,
+		},
+		{
+			Name: "constraint references unknown variable",
+			Variables: []Variable{
+				variable("a", Dependency("b")),
+			},
+			Error: `1 errors encountered: variable "b" referenced but not provided`,
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			violated, err := Verify(tt.Variables, tt.Selected, tt.Constraints...)
+			if tt.Error != "" {
+				assert.EqualError(t, err, tt.Error)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.Violated, violated)
+		})
+	}
+}
+
+func TestVerifyDifferential(t *testing.T) {
+	const (
+		iterations = 500
+		length     = 10
+	)
+
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < iterations; i++ {
+		variables, globals := randomInput(rng, length)
+
+		var selected []Identifier
+		set := make(map[Identifier]bool)
+		for _, variable := range variables {
+			if rng.Intn(2) == 0 {
+				selected = append(selected, variable.Identifier())
+				set[variable.Identifier()] = true
+			}
+		}
+		isSelected := func(id Identifier) bool {
+			return set[id]
+		}
+
+		var expected []AppliedConstraint
+		for _, variable := range variables {
+			for _, constraint := range variable.Constraints() {
+				if !holds(constraint, variable.Identifier(), isSelected) {
+					expected = append(expected, AppliedConstraint{Variable: variable, Constraint: constraint})
+				}
+			}
+		}
+		for _, constraint := range globals {
+			if !holds(constraint, "", isSelected) {
+				expected = append(expected, AppliedConstraint{Constraint: constraint})
+			}
+		}
+
+		violated, err := Verify(variables, selected, globals...)
+		require.NoError(t, err)
+		assert.Equal(t, expected, violated, "iteration %d", i)
+
+		// Solutions found by Solve never violate a constraint.
+		s, err := New(WithInput(variables), WithGlobalConstraints(globals...))
+		require.NoError(t, err)
+		installed, err := s.Solve(context.Background())
+		if err != nil {
+			continue
+		}
+		selected = nil
+		for _, variable := range installed {
+			selected = append(selected, variable.Identifier())
+		}
+		violated, err = Verify(variables, selected, globals...)
+		require.NoError(t, err)
+		assert.Empty(t, violated, "iteration %d", i)
+	}
+}
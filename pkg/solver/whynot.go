@@ -0,0 +1,92 @@
+package solver
+
+import (
+	"context"
+	"errors"
+)
+
+// Counterfactual describes the outcome of solving a problem as if a
+// Variable that it does not otherwise select were required.
+type Counterfactual struct {
+	// Identifier identifies the required Variable.
+	Identifier Identifier
+	// Conflict is non-nil if no solution selects the Variable,
+	// in which case it contains applied constraints that cannot
+	// all hold when the Variable is selected.
+	Conflict NotSatisfiable
+	// Result describes the solution found when the Variable is
+	// required, if Conflict is nil.
+	Result Result
+	// Costs contains, for each value in Result.Objectives, the
+	// value achieved in Result less the value achieved without
+	// requiring the Variable. ImplicitCosts does the same for
+	// Result.ImplicitObjectives. If Conflict is nil and no cost
+	// is positive, the Variable was left out because of search
+	// preference or cardinality rather than objectives.
+	Costs         []ObjectiveValue
+	ImplicitCosts []ObjectiveValue
+}
+
+// WhyNot explains why the Variable with the given Identifier is not
+// part of the solution found by Solve, by solving the problem again
+// as if that Variable were Mandatory. If the Variable is selected by
+// Solve, the returned Counterfactual describes that solution and has
+// no costs. Errors from solving the problem without the Variable
+// required, including NotSatisfiable, are returned as is.
+func (s *solver) WhyNot(ctx context.Context, id Identifier) (result Counterfactual, err error) {
+	defer func() {
+		// This likely indicates a bug, so discard whatever
+		// return values were produced.
+		if derr := s.litMap.Error(); derr != nil {
+			result = Counterfactual{}
+			err = derr
+		}
+	}()
+
+	ms, err := s.assumptionLits([]Assumption{AssumeMandatory(id)})
+	if err != nil {
+		return Counterfactual{}, err
+	}
+
+	s.prepare()
+	baseline, err := s.solve(ctx)
+	if err != nil {
+		return Counterfactual{}, err
+	}
+	result.Identifier = id
+	for _, variable := range baseline.Selected {
+		if variable.Identifier() == id {
+			result.Result = baseline
+			return result, nil
+		}
+	}
+
+	s.litMap.Require(ms)
+	result.Result, err = s.solve(ctx)
+	s.litMap.Require(nil)
+	var ns NotSatisfiable
+	if errors.As(err, &ns) {
+		result.Conflict = ns
+		return result, nil
+	}
+	if err != nil {
+		return Counterfactual{}, err
+	}
+
+	result.Costs = costs(result.Result.Objectives, baseline.Objectives)
+	result.ImplicitCosts = costs(result.Result.ImplicitObjectives, baseline.ImplicitObjectives)
+	return result, nil
+}
+
+// costs returns the differences between corresponding objective
+// values.
+func costs(values, baseline []ObjectiveValue) []ObjectiveValue {
+	var result []ObjectiveValue
+	for i, value := range values {
+		result = append(result, ObjectiveValue{
+			Objective: value.Objective,
+			Value:     value.Value - baseline[i].Value,
+		})
+	}
+	return result
+}
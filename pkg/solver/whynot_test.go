@@ -0,0 +1,180 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhyNot(t *testing.T) {
+	type tc struct {
+		Name       string
+		Variables  []Variable
+		Objectives []Objective
+		Minimal    bool
+		ID         Identifier
+		Conflict   []string
+		Installed  []Identifier
+		Costs      []int
+		Implicit   []int
+		Error      error
+	}
+
+	for _, tt := range []tc{
+		{
+			Name: "already selected",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x"),
+				variable("y"),
+			},
+			ID:        "x",
+			Installed: []Identifier{"a", "x"},
+		},
+		{
+			Name: "conflict",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y")),
+				variable("x", Conflict("a")),
+				variable("y"),
+			},
+			Minimal:  true,
+			ID:       "x",
+			Conflict: []string{"x conflicts with a", "a is mandatory"},
+		},
+		{
+			Name: "objective cost",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("deprecated", "x")),
+				variable("deprecated"),
+				variable("x"),
+			},
+			Objectives: []Objective{MinimizeSelected("deprecated")},
+			ID:         "deprecated",
+			Installed:  []Identifier{"a", "deprecated"},
+			Costs:      []int{1},
+		},
+		{
+			Name: "soft constraint cost",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y"), Soft(3, Conflict("x"))),
+				variable("x"),
+				variable("y"),
+			},
+			ID:        "x",
+			Installed: []Identifier{"a", "x"},
+			Implicit:  []int{3},
+		},
+		{
+			Name: "soft constraint and objective costs",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y"), Soft(3, Conflict("x"))),
+				variable("x"),
+				variable("y", Conflict("x")),
+			},
+			Objectives: []Objective{MaximizeSelected("y")},
+			ID:         "x",
+			Installed:  []Identifier{"a", "x"},
+			Costs:      []int{1},
+			Implicit:   []int{3},
+		},
+		{
+			Name: "not needed",
+			Variables: []Variable{
+				variable("a", Mandatory()),
+				variable("b"),
+			},
+			Objectives: []Objective{MinimizeSize()},
+			ID:         "b",
+			Installed:  []Identifier{"a", "b"},
+			Costs:      []int{1},
+		},
+		{
+			Name: "preference",
+			Variables: []Variable{
+				variable("a", Mandatory(), Dependency("x", "y"), Dependency("y", "z")),
+				variable("x"),
+				variable("y"),
+				variable("z"),
+			},
+			ID:        "z",
+			Installed: []Identifier{"a", "x", "z"},
+		},
+		{
+			Name: "not satisfiable",
+			Variables: []Variable{
+				variable("a", Mandatory(), Prohibited()),
+				variable("b"),
+			},
+			ID:    "b",
+			Error: NotSatisfiable{},
+		},
+		{
+			Name: "unknown variable",
+			Variables: []Variable{
+				variable("a"),
+			},
+			ID:    "missing",
+			Error: errors.New(`variable "missing" not provided`),
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			options := []Option{WithInput(tt.Variables), WithObjectives(tt.Objectives...)}
+			if tt.Minimal {
+				options = append(options, WithMinimalConflicts())
+			}
+			s, err := New(options...)
+			if err != nil {
+				t.Fatalf("failed to initialize solver: %s", err)
+			}
+
+			counterfactual, err := s.WhyNot(context.TODO(), tt.ID)
+			if tt.Error != nil {
+				if _, ok := tt.Error.(NotSatisfiable); ok {
+					assert.IsType(tt.Error, err)
+				} else {
+					assert.EqualError(err, tt.Error.Error())
+				}
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tt.ID, counterfactual.Identifier)
+
+			var conflict []string
+			for _, a := range counterfactual.Conflict {
+				conflict = append(conflict, a.String())
+			}
+			assert.ElementsMatch(tt.Conflict, conflict)
+
+			var ids []Identifier
+			for _, variable := range counterfactual.Result.Selected {
+				ids = append(ids, variable.Identifier())
+			}
+			assert.Equal(tt.Installed, ids)
+
+			var costs []int
+			for _, c := range counterfactual.Costs {
+				costs = append(costs, c.Value)
+			}
+			assert.Equal(tt.Costs, costs)
+			var implicit []int
+			for _, c := range counterfactual.ImplicitCosts {
+				implicit = append(implicit, c.Value)
+			}
+			assert.Equal(tt.Implicit, implicit)
+
+			// The requirement does not outlive the call.
+			installed, err := s.Solve(context.TODO())
+			assert.NoError(err)
+			for _, variable := range installed {
+				if tt.Conflict != nil || tt.Costs != nil || tt.Implicit != nil {
+					assert.NotEqual(tt.ID, variable.Identifier())
+				}
+			}
+		})
+	}
+}